@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AgDecode/mini-game/game"
+)
+
+type subcommand func(args []string) int
+
+var subcommands = map[string]subcommand{
+	"replay": runReplay,
+	"map":    runMap,
+}
+
+func loadRecording(name string, args []string) (*game.Recording, bool) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "использование: %s <журнал>\n", name)
+		return nil, false
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+	defer file.Close()
+
+	recording, err := game.LoadRecording(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+	if recording.Version != game.WorldVersion {
+		fmt.Fprintf(os.Stderr, "журнал записан версией мира %s, текущая %s\n", recording.Version, game.WorldVersion)
+	}
+	return recording, true
+}
+
+func runReplay(args []string) int {
+	recording, ok := loadRecording("replay", args)
+	if !ok {
+		return 2
+	}
+
+	if _, divergence := game.Replay(recording, len(recording.Steps)); divergence != nil {
+		fmt.Println("расхождение:", divergence)
+		return 1
+	}
+	fmt.Printf("журнал воспроизведён: шагов %d\n", len(recording.Steps))
+	return 0
+}
+
+func runMap(args []string) int {
+	flags := flag.NewFlagSet("map", flag.ExitOnError)
+	format := flags.String("format", "dot", "формат карты: dot или ascii")
+	flags.Parse(args)
+
+	state := game.InitGame()
+	switch *format {
+	case "dot":
+		fmt.Print(game.RenderDOT(state))
+	case "ascii":
+		fmt.Println(game.RenderASCIIMap(state, false))
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестный формат %q\n", *format)
+		return 2
+	}
+	return 0
+}
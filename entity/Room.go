@@ -11,15 +11,23 @@ type RoomBehavior struct {
 }
 
 type Room struct {
-	Name         string
-	Description  string
-	EnterMessage string
-	Items        map[string][]*Item
-	Connections  map[string]*Room
-	Traits       map[string]interface{}
-	Emitter      *EventEmitter
-	WasVisited   bool
-	HasHint      bool
+	Name           string
+	Description    string
+	EnterMessage   string
+	RevisitMessage string // При повторном входе; пусто - EnterMessage
+	BriefMessage   string // При повторном входе в режиме "кратко"
+	TimeMessages   map[string]string
+	LookTemplate   string   // Шаблон text/template для "осмотреться"
+	Places         []string // Места в порядке появления, ключи Items
+	Items          map[string][]*Item
+	Furniture      map[string]*Furniture // По ключу места
+	Connections    map[string]*Room      // По названию комнаты
+	Directions     map[string]*Room      // По стороне света
+	Locks          map[string]string     // Запертые переходы: название комнаты -> причина
+	Traits         map[string]interface{}
+	Emitter        *EventEmitter
+	WasVisited     bool
+	HasHint        bool
 }
 
 func NewRoom(name, description string, enterMessage string) *Room {
@@ -27,8 +35,11 @@ func NewRoom(name, description string, enterMessage string) *Room {
 		Name:         name,
 		Description:  description,
 		EnterMessage: enterMessage,
+		TimeMessages: make(map[string]string),
 		Items:        make(map[string][]*Item),
+		Furniture:    make(map[string]*Furniture),
 		Connections:  make(map[string]*Room),
+		Directions:   make(map[string]*Room),
 		Traits:       make(map[string]interface{}),
 		Emitter:      NewEventEmitter(),
 	}
@@ -83,48 +94,84 @@ func (r *Room) SetTrait(trait string, value interface{}) {
 	r.Traits[trait] = value
 }
 
-func (r *Room) OnEnter(player *Player) string {
-	event := &Event{
-		Type:   EventRoomEntered,
-		Source: player,
-		Target: r,
-		Data:   make(map[string]interface{}),
+// OnEnter сообщает о входе игрока; отменить вход можно только в before_move.
+func (r *Room) OnEnter(player *Player, payload MovePayload) string {
+	_ = r.Emitter.Emit(NewEvent(EventRoomEntered, player, r, payload))
+	return r.GetDescription()
+}
+
+func (r *Room) OnExit(player *Player, payload MovePayload) string {
+	_ = r.Emitter.Emit(NewEvent(EventRoomExited, player, r, payload))
+	return ""
+}
+
+func (r *Room) AddFurniture(furniture *Furniture) {
+	if r.Furniture == nil {
+		r.Furniture = make(map[string]*Furniture)
 	}
-	err := r.Emitter.Emit(event)
-	if err != nil {
-		return ""
+	if r.Items == nil {
+		r.Items = make(map[string][]*Item)
 	}
-	if event.Prevented {
-		return event.Data["message"].(string)
+	place := furniture.Place()
+	r.Furniture[place] = furniture
+	if _, ok := r.Items[place]; !ok {
+		r.Places = append(r.Places, place)
+		r.Items[place] = nil
 	}
-	return r.GetDescription()
 }
 
-func (r *Room) OnExit(player *Player) string {
-	event := &Event{
-		Type:   EventRoomExited,
-		Source: player,
-		Target: r,
-		Data:   make(map[string]interface{}),
+// FindFurniture ищет мебель по любой падежной форме названия.
+func (r *Room) FindFurniture(name string) *Furniture {
+	for _, place := range r.Places {
+		if furniture := r.Furniture[place]; furniture != nil && furniture.Matches(name) {
+			return furniture
+		}
 	}
-	err := r.Emitter.Emit(event)
-	if err != nil {
-		return ""
+	return nil
+}
+
+// IsVisible сообщает, видно ли предметы в месте place; места без мебели видны всегда.
+func (r *Room) IsVisible(place string) bool {
+	furniture := r.Furniture[place]
+	return furniture == nil || furniture.ContentsVisible()
+}
+
+func (r *Room) HasSpace(place string) bool {
+	furniture := r.Furniture[place]
+	return furniture == nil || furniture.HasSpace(len(r.Items[place]))
+}
+
+// VisibleItems возвращает предметы в открытых местах, кроме ещё не найденных.
+func (r *Room) VisibleItems() []*Item {
+	var items []*Item
+	for _, place := range r.Places {
+		items = append(items, r.VisibleItemsAt(place)...)
 	}
-	return ""
+	return items
+}
+
+func (r *Room) VisibleItemsAt(place string) []*Item {
+	if !r.IsVisible(place) {
+		return nil
+	}
+	var items []*Item
+	for _, item := range r.Items[place] {
+		if !item.IsHidden() {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (r *Room) AddItem(item *Item, place string) {
 	if r.Items == nil {
 		r.Items = make(map[string][]*Item)
 	}
-	r.Items[place] = append(r.Items[place], item)
-	event := &Event{
-		Type:   EventItemDropped,
-		Source: item,
-		Target: r,
-		Data:   make(map[string]interface{}),
+	if _, ok := r.Items[place]; !ok {
+		r.Places = append(r.Places, place)
 	}
+	r.Items[place] = append(r.Items[place], item)
+	event := NewEvent(EventItemDropped, item, r, ItemPayload{Item: item, Place: place})
 	err := r.Emitter.Emit(event)
 	if err != nil {
 		return
@@ -136,12 +183,7 @@ func (r *Room) RemoveItem(item *Item, place string) {
 		for i, it := range items {
 			if it == item {
 				r.Items[place] = append(items[:i], items[i+1:]...)
-				event := &Event{
-					Type:   EventItemPicked,
-					Source: item,
-					Target: r,
-					Data:   make(map[string]interface{}),
-				}
+				event := NewEvent(EventItemPicked, item, r, ItemPayload{Item: item, Place: place})
 				err := r.Emitter.Emit(event)
 				if err != nil {
 					return
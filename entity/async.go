@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type BackpressurePolicy int
+
+const (
+	BackpressureBlock      BackpressurePolicy = iota // Ждать, пока в очереди освободится место
+	BackpressureDropNewest                           // Отбросить новое событие
+	BackpressureDropOldest                           // Вытеснить самое старое событие из очереди
+)
+
+const DefaultQueueSize = 256
+
+type AsyncOptions struct {
+	QueueSize int
+	Policy    BackpressurePolicy
+	OnError   func(error)
+}
+
+// AsyncSubscriber получает копии событий в своей горутине, в порядке их отправки.
+// Он видит событие уже после синхронных обработчиков и не может его отменить.
+type AsyncSubscriber struct {
+	eventType EventType
+	handler   EventHandler
+	options   AsyncOptions
+	emitter   *EventEmitter
+	queue     chan *Event
+	pending   sync.WaitGroup
+	mu        sync.Mutex
+	closed    bool
+	done      chan struct{}
+	dropped   atomic.Uint64
+}
+
+func (e *EventEmitter) OnAsync(eventType EventType, handler EventHandler, options AsyncOptions) *AsyncSubscriber {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultQueueSize
+	}
+
+	sub := &AsyncSubscriber{
+		eventType: eventType,
+		handler:   handler,
+		options:   options,
+		emitter:   e,
+		queue:     make(chan *Event, options.QueueSize),
+		done:      make(chan struct{}),
+	}
+	e.observers = append(e.observers, sub)
+
+	go sub.run()
+
+	return sub
+}
+
+func (s *AsyncSubscriber) run() {
+	defer close(s.done)
+	for event := range s.queue {
+		if err := s.handler(event); err != nil && s.options.OnError != nil {
+			s.options.OnError(err)
+		}
+		s.pending.Done()
+	}
+}
+
+func (s *AsyncSubscriber) enqueue(event *Event) {
+	if s.eventType != AnyEvent && s.eventType != event.Type {
+		return
+	}
+
+	snapshot := *event
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.pending.Add(1)
+	switch s.options.Policy {
+	case BackpressureDropNewest:
+		select {
+		case s.queue <- &snapshot:
+		default:
+			s.pending.Done()
+			s.dropped.Add(1)
+		}
+	case BackpressureDropOldest:
+		select {
+		case s.queue <- &snapshot:
+		default:
+			select {
+			case <-s.queue:
+				s.pending.Done()
+				s.dropped.Add(1)
+			default:
+			}
+			s.queue <- &snapshot
+		}
+	default:
+		s.queue <- &snapshot
+	}
+}
+
+func (s *AsyncSubscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Flush ждёт, пока подписчик обработает все уже поставленные в очередь события.
+func (s *AsyncSubscriber) Flush() {
+	s.pending.Wait()
+}
+
+func (s *AsyncSubscriber) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.done
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	s.emitter.removeObserver(s)
+}
+
+func (e *EventEmitter) removeObserver(sub *AsyncSubscriber) {
+	for i, observer := range e.observers {
+		if observer == sub {
+			e.observers = append(e.observers[:i:i], e.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *EventEmitter) notifyObservers(event *Event) {
+	for _, observer := range e.observers {
+		observer.enqueue(event)
+	}
+}
+
+func (e *EventEmitter) Flush() {
+	for _, observer := range e.observers {
+		observer.Flush()
+	}
+}
+
+// Close дожидается доставки всех асинхронных событий и останавливает подписчиков.
+func (e *EventEmitter) Close() {
+	observers := append([]*AsyncSubscriber(nil), e.observers...)
+	for _, observer := range observers {
+		observer.Close()
+	}
+}
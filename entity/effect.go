@@ -0,0 +1,108 @@
+package entity
+
+const (
+	AttrHealth = "health"
+	AttrEnergy = "energy"
+	AttrHunger = "hunger"
+
+	AttrMin = 0
+	AttrMax = 100
+)
+
+type StatusEffect struct {
+	Name      string
+	Duration  int // Оставшиеся ходы, 0 - действует бессрочно
+	Modifiers map[string]int
+}
+
+func NewStatusEffect(name string, duration int, modifiers map[string]int) *StatusEffect {
+	if modifiers == nil {
+		modifiers = make(map[string]int)
+	}
+	return &StatusEffect{
+		Name:      name,
+		Duration:  duration,
+		Modifiers: modifiers,
+	}
+}
+
+type Consumable struct {
+	Kind       string // "food" или "drink"
+	Attributes map[string]int
+	Effect     *StatusEffect
+}
+
+func (p *Player) GetAttribute(name string) int {
+	return p.Attributes[name]
+}
+
+func (p *Player) SetAttribute(name string, value int) {
+	if value < AttrMin {
+		value = AttrMin
+	}
+	if value > AttrMax {
+		value = AttrMax
+	}
+	p.Attributes[name] = value
+}
+
+func (p *Player) ChangeAttribute(name string, delta int) {
+	p.SetAttribute(name, p.Attributes[name]+delta)
+}
+
+func (p *Player) HasEffect(name string) bool {
+	return p.GetEffect(name) != nil
+}
+
+func (p *Player) GetEffect(name string) *StatusEffect {
+	for _, effect := range p.Effects {
+		if effect.Name == name {
+			return effect
+		}
+	}
+	return nil
+}
+
+func (p *Player) AddEffect(effect *StatusEffect) {
+	p.RemoveEffect(effect.Name)
+	copied := NewStatusEffect(effect.Name, effect.Duration, make(map[string]int, len(effect.Modifiers)))
+	for attr, delta := range effect.Modifiers {
+		copied.Modifiers[attr] = delta
+	}
+	p.Effects = append(p.Effects, copied)
+}
+
+func (p *Player) RemoveEffect(name string) {
+	for i, effect := range p.Effects {
+		if effect.Name == name {
+			p.Effects = append(p.Effects[:i], p.Effects[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *Player) TickEffects() {
+	active := p.Effects[:0]
+	for _, effect := range p.Effects {
+		for attr, delta := range effect.Modifiers {
+			p.ChangeAttribute(attr, delta)
+		}
+		if effect.Duration > 0 {
+			effect.Duration--
+			if effect.Duration == 0 {
+				continue
+			}
+		}
+		active = append(active, effect)
+	}
+	p.Effects = active
+}
+
+func (p *Player) Consume(consumable *Consumable) {
+	for attr, delta := range consumable.Attributes {
+		p.ChangeAttribute(attr, delta)
+	}
+	if consumable.Effect != nil {
+		p.AddEffect(consumable.Effect)
+	}
+}
@@ -1,71 +1,226 @@
 package entity
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type EventType string
 
 const (
-	EventItemPicked  EventType = "item_picked"
-	EventItemDropped EventType = "item_dropped"
-	EventItemUsed    EventType = "item_used"
-	EventRoomEntered EventType = "room_entered"
-	EventRoomExited  EventType = "room_exited"
+	EventItemPicked          EventType = "item_picked"
+	EventItemDropped         EventType = "item_dropped"
+	EventItemUsed            EventType = "item_used"
+	EventItemWorn            EventType = "item_worn"
+	EventItemConsumed        EventType = "item_consumed"
+	EventDoorOpened          EventType = "door_opened"
+	EventRoomEntered         EventType = "room_entered"
+	EventRoomExited          EventType = "room_exited"
+	EventBeforeMove          EventType = "before_move"
+	EventAfterMove           EventType = "after_move"
+	EventBeforePickup        EventType = "before_pickup"
+	EventObjectiveCompleted  EventType = "objective_completed"
+	EventAchievementUnlocked EventType = "achievement_unlocked"
+	EventFurnitureOpened     EventType = "furniture_opened"
+	EventFurnitureClosed     EventType = "furniture_closed"
+	EventItemRevealed        EventType = "item_revealed"
+	EventLightOut            EventType = "light_out"
+
+	AnyEvent EventType = "*"
+)
+
+type Phase int
+
+const (
+	PhaseAfter  Phase = iota // Информационное событие, отменить нельзя
+	PhaseBefore              // Событие до действия, обработчик может его отменить
 )
 
 type Event struct {
 	Type      EventType
+	Phase     Phase
 	Source    interface{} // Объект, вызвавший событие
 	Target    interface{} // Объект, на который направлено событие
-	Data      map[string]interface{}
+	Payload   interface{}
 	Prevented bool
+	Message   string // Причина отмены для игрока
+	Probe     bool   // Только проверка "можно ли": действия не будет, журналы и асинхронные подписчики её не видят
+}
+
+type MovePayload struct {
+	From      *Room
+	To        *Room
+	Direction string // Полная сторона света; пусто, если у перехода её нет
+}
+
+type ItemPayload struct {
+	Item  *Item
+	Place string
+}
+
+type UsePayload struct {
+	Item   *Item
+	Target interface{}
+}
+
+func NewEvent(eventType EventType, source, target, payload interface{}) *Event {
+	return &Event{
+		Type:    eventType,
+		Phase:   PhaseAfter,
+		Source:  source,
+		Target:  target,
+		Payload: payload,
+	}
+}
+
+func NewBeforeEvent(eventType EventType, source, target, payload interface{}) *Event {
+	event := NewEvent(eventType, source, target, payload)
+	event.Phase = PhaseBefore
+	return event
+}
+
+func (e *Event) Cancelable() bool {
+	return e.Phase == PhaseBefore
+}
+
+// Prevent отменяет действие; для информационных событий ничего не делает.
+func (e *Event) Prevent(message string) {
+	if !e.Cancelable() {
+		return
+	}
+	e.Prevented = true
+	e.Message = message
+}
+
+func PayloadOf[T any](event *Event) (T, bool) {
+	payload, ok := event.Payload.(T)
+	return payload, ok
 }
 
 type EventHandler func(*Event) error
 
+type Subscription struct {
+	eventType EventType
+	id        uint64
+}
+
+type subscriber struct {
+	id       uint64
+	priority int
+	handler  EventHandler
+}
+
 type EventEmitter struct {
-	handlers map[EventType][]EventHandler
+	handlers  map[EventType][]subscriber
+	observers []*AsyncSubscriber
+	nextID    uint64
+	parent    *EventEmitter
 }
 
 func NewEventEmitter() *EventEmitter {
 	return &EventEmitter{
-		handlers: make(map[EventType][]EventHandler),
+		handlers: make(map[EventType][]subscriber),
 	}
 }
 
-func (e *EventEmitter) On(eventType EventType, handler EventHandler) {
-	if e.handlers[eventType] == nil {
-		e.handlers[eventType] = make([]EventHandler, 0)
+// SetParent пробрасывает все события этого эмиттера в parent после локальных обработчиков.
+func (e *EventEmitter) SetParent(parent *EventEmitter) {
+	if parent == e {
+		return
+	}
+	e.parent = parent
+}
+
+func (e *EventEmitter) On(eventType EventType, handler EventHandler) Subscription {
+	return e.OnPriority(eventType, 0, handler)
+}
+
+// OnPriority подписывает обработчик; обработчики с большим приоритетом вызываются раньше.
+func (e *EventEmitter) OnPriority(eventType EventType, priority int, handler EventHandler) Subscription {
+	e.nextID++
+	sub := subscriber{id: e.nextID, priority: priority, handler: handler}
+
+	handlers := make([]subscriber, 0, len(e.handlers[eventType])+1)
+	handlers = append(handlers, e.handlers[eventType]...)
+	handlers = append(handlers, sub)
+	sort.SliceStable(handlers, func(i, j int) bool {
+		return handlers[i].priority > handlers[j].priority
+	})
+	e.handlers[eventType] = handlers
+
+	return Subscription{eventType: eventType, id: sub.id}
+}
+
+func (e *EventEmitter) OnAny(handler EventHandler) Subscription {
+	return e.On(AnyEvent, handler)
+}
+
+func Subscribe[T any](e *EventEmitter, eventType EventType, handler func(*Event, T) error) Subscription {
+	return e.On(eventType, func(event *Event) error {
+		payload, ok := PayloadOf[T](event)
+		if !ok {
+			return nil
+		}
+		return handler(event, payload)
+	})
+}
+
+func (e *EventEmitter) Off(sub Subscription) {
+	handlers := e.handlers[sub.eventType]
+	for i, h := range handlers {
+		if h.id == sub.id {
+			e.handlers[sub.eventType] = append(handlers[:i:i], handlers[i+1:]...)
+			return
+		}
 	}
-	e.handlers[eventType] = append(e.handlers[eventType], handler)
 }
 
 func (e *EventEmitter) Emit(event *Event) error {
-	handlers := e.handlers[event.Type]
-	if handlers == nil {
-		return nil
+	err := e.dispatch(event)
+	if !event.Probe {
+		e.notifyObservers(event)
+	}
+	return err
+}
+
+// Probe спрашивает обработчики, разрешили бы они действие, не выполняя его.
+func (e *EventEmitter) Probe(eventType EventType, source, target, payload interface{}) (string, bool) {
+	event := NewBeforeEvent(eventType, source, target, payload)
+	event.Probe = true
+	if err := e.Emit(event); err != nil {
+		return err.Error(), true
 	}
+	return event.Message, event.Prevented
+}
 
-	for _, handler := range handlers {
-		if err := handler(event); err != nil {
+func (e *EventEmitter) dispatch(event *Event) error {
+	for _, h := range e.subscribers(event.Type) {
+		if err := h.handler(event); err != nil {
 			return fmt.Errorf("error handling event %s: %v", event.Type, err)
 		}
 		if event.Prevented {
-			break
+			return nil
 		}
 	}
+
+	if e.parent != nil {
+		return e.parent.Emit(event)
+	}
 	return nil
 }
 
-func (e *EventEmitter) RemoveHandler(eventType EventType, handler EventHandler) {
-	handlers := e.handlers[eventType]
-	if handlers == nil {
-		return
+func (e *EventEmitter) subscribers(eventType EventType) []subscriber {
+	typed := e.handlers[eventType]
+	wildcard := e.handlers[AnyEvent]
+	if eventType == AnyEvent || len(wildcard) == 0 {
+		return typed
 	}
 
-	for i, h := range handlers {
-		if fmt.Sprintf("%v", h) == fmt.Sprintf("%v", handler) {
-			e.handlers[eventType] = append(handlers[:i], handlers[i+1:]...)
-			break
-		}
-	}
+	merged := make([]subscriber, 0, len(typed)+len(wildcard))
+	merged = append(merged, typed...)
+	merged = append(merged, wildcard...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].priority > merged[j].priority
+	})
+	return merged
 }
@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEventEmitter(t *testing.T) {
+	emitter := NewEventEmitter()
+	var calls []string
+
+	record := func(name string) EventHandler {
+		return func(event *Event) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	emitter.On(EventBeforeMove, record("обычный"))
+	removed := emitter.On(EventBeforeMove, record("удалённый"))
+	emitter.OnPriority(EventBeforeMove, 10, record("важный"))
+	emitter.OnAny(record("любой"))
+	emitter.Off(removed)
+
+	emitter.OnPriority(EventBeforeMove, 5, func(event *Event) error {
+		calls = append(calls, "запрет")
+		event.Prevent("нельзя уйти")
+		return nil
+	})
+
+	before := NewBeforeEvent(EventBeforeMove, nil, nil, MovePayload{})
+	if err := emitter.Emit(before); err != nil {
+		t.Fatal(err)
+	}
+	if !before.Prevented || before.Message != "нельзя уйти" {
+		t.Error("before event was not prevented:", before.Prevented, before.Message)
+	}
+	if expected := []string{"важный", "запрет"}; !reflect.DeepEqual(calls, expected) {
+		t.Error("\n\tresult:  ", calls, "\n\texpected:", expected)
+	}
+
+	calls = nil
+	after := NewEvent(EventAfterMove, nil, nil, MovePayload{})
+	after.Prevent("поздно")
+	Subscribe(emitter, EventAfterMove, func(event *Event, payload MovePayload) error {
+		calls = append(calls, "типизированный")
+		return nil
+	})
+	if err := emitter.Emit(after); err != nil {
+		t.Fatal(err)
+	}
+	if after.Prevented {
+		t.Error("after event must not be cancelable")
+	}
+	if expected := []string{"типизированный", "любой"}; !reflect.DeepEqual(calls, expected) {
+		t.Error("\n\tresult:  ", calls, "\n\texpected:", expected)
+	}
+}
+
+func TestAsyncSubscriber(t *testing.T) {
+	emitter := NewEventEmitter()
+
+	var received []EventType
+	ordered := emitter.OnAsync(AnyEvent, func(event *Event) error {
+		received = append(received, event.Type)
+		return nil
+	}, AsyncOptions{QueueSize: 2})
+
+	gate := make(chan struct{})
+	delivered := 0
+	dropping := emitter.OnAsync(EventAfterMove, func(event *Event) error {
+		<-gate
+		delivered++
+		return nil
+	}, AsyncOptions{QueueSize: 1, Policy: BackpressureDropNewest})
+
+	expected := []EventType{EventBeforeMove, EventAfterMove, EventAfterMove, EventAfterMove}
+	for _, eventType := range expected {
+		if err := emitter.Emit(NewEvent(eventType, nil, nil, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(gate)
+	emitter.Close()
+
+	if !reflect.DeepEqual(received, expected) {
+		t.Error("\n\tresult:  ", received, "\n\texpected:", expected)
+	}
+	if ordered.Dropped() != 0 {
+		t.Error("blocking subscriber dropped events:", ordered.Dropped())
+	}
+	if dropping.Dropped() == 0 || delivered+int(dropping.Dropped()) != 3 {
+		t.Error("delivered:", delivered, "dropped:", dropping.Dropped())
+	}
+}
+
+func TestPlayerMove(t *testing.T) {
+	kitchen, corridor := NewRoom("кухня", "", ""), NewRoom("коридор", "", "")
+	player := NewPlayer(kitchen)
+	var calls []EventType
+	for _, emitter := range []*EventEmitter{player.Emitter, kitchen.Emitter, corridor.Emitter} {
+		emitter.OnAny(func(event *Event) error {
+			calls = append(calls, event.Type)
+			return nil
+		})
+	}
+
+	corridor.Emitter.On(EventRoomEntered, func(event *Event) error {
+		event.Prevent("поздно")
+		return nil
+	})
+	if _, ok := player.Move(corridor, "коридор"); !ok || player.CurrentRoom != corridor {
+		t.Error("room_entered must not cancel a committed move")
+	}
+	if expected := []EventType{EventBeforeMove, EventRoomExited, EventRoomEntered, EventAfterMove}; !reflect.DeepEqual(calls, expected) {
+		t.Error("\n\tresult:  ", calls, "\n\texpected:", expected)
+	}
+
+	calls = nil
+	player.Emitter.On(EventBeforeMove, func(event *Event) error {
+		return errors.New("сломалось")
+	})
+	answer, ok := player.Move(kitchen, "кухня")
+	if ok || answer != MsgMoveFailed || player.CurrentRoom != corridor {
+		t.Error("failed move:", answer, ok, player.CurrentRoom.Name)
+	}
+	for _, eventType := range calls {
+		if eventType != EventBeforeMove {
+			t.Error("failed move emitted", eventType)
+		}
+	}
+}
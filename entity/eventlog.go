@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type EventRecord struct {
+	Seq       uint64    `json:"seq"`
+	Session   string    `json:"session"`
+	Time      time.Time `json:"time"`
+	Type      EventType `json:"type"`
+	Phase     string    `json:"phase"`
+	Source    string    `json:"source,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Prevented bool      `json:"prevented,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// EventLog дописывает каждое событие шины в файл в формате JSON Lines.
+type EventLog struct {
+	file       *os.File
+	writer     *bufio.Writer
+	encoder    *json.Encoder
+	session    string
+	seq        uint64
+	subscriber *AsyncSubscriber
+	err        error
+}
+
+func NewSessionID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+func OpenEventLog(path, session string) (*EventLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if session == "" {
+		session = NewSessionID()
+	}
+
+	writer := bufio.NewWriter(file)
+	return &EventLog{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+		session: session,
+	}, nil
+}
+
+func (l *EventLog) Session() string {
+	return l.session
+}
+
+func (l *EventLog) Attach(emitter *EventEmitter, options AsyncOptions) {
+	if l.subscriber != nil {
+		l.subscriber.Close()
+	}
+	l.subscriber = emitter.OnAsync(AnyEvent, l.write, options)
+}
+
+func (l *EventLog) write(event *Event) error {
+	l.seq++
+	record := EventRecord{
+		Seq:       l.seq,
+		Session:   l.session,
+		Time:      time.Now().UTC(),
+		Type:      event.Type,
+		Phase:     phaseName(event.Phase),
+		Source:    EntityName(event.Source),
+		Target:    EntityName(event.Target),
+		Prevented: event.Prevented,
+		Message:   event.Message,
+	}
+	if err := l.encoder.Encode(record); err != nil {
+		l.err = err
+		return err
+	}
+	return nil
+}
+
+func (l *EventLog) Dropped() uint64 {
+	if l.subscriber == nil {
+		return 0
+	}
+	return l.subscriber.Dropped()
+}
+
+// Close доставляет оставшиеся события, сбрасывает буфер на диск и закрывает файл.
+func (l *EventLog) Close() error {
+	if l.subscriber != nil {
+		l.subscriber.Close()
+	}
+	if err := l.writer.Flush(); err != nil {
+		l.file.Close()
+		return err
+	}
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	return l.err
+}
+
+func phaseName(phase Phase) string {
+	if phase == PhaseBefore {
+		return "before"
+	}
+	return "after"
+}
+
+func EntityName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *Room:
+		return v.Name
+	case *Item:
+		return v.Name
+	case *Player:
+		return "игрок"
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
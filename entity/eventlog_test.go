@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	kitchen, corridor := NewRoom("кухня", "", ""), NewRoom("коридор", "", "")
+
+	var sessions []string
+	for i := 0; i < 2; i++ {
+		log, err := OpenEventLog(path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, log.Session())
+
+		emitter := NewEventEmitter()
+		log.Attach(emitter, AsyncOptions{Policy: BackpressureBlock})
+
+		before := NewBeforeEvent(EventBeforeMove, NewPlayer(kitchen), corridor, nil)
+		before.Prevent("нельзя")
+		for _, event := range []*Event{before, NewEvent(EventAfterMove, kitchen, corridor, nil)} {
+			if err := emitter.Emit(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Close без Flush: всё поставленное в очередь должно попасть в файл.
+		if err := log.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sessions[0] == "" || sessions[0] == sessions[1] {
+		t.Fatal("session ids:", sessions)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records []EventRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record EventRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		record.Time = record.Time.UTC()
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatal("records:", records)
+	}
+
+	for i, record := range records {
+		session := sessions[i/2]
+		expected := EventRecord{Seq: uint64(i%2 + 1), Session: session, Time: record.Time, Type: EventAfterMove, Phase: "after", Source: "кухня", Target: "коридор"}
+		if i%2 == 0 {
+			expected = EventRecord{Seq: 1, Session: session, Time: record.Time, Type: EventBeforeMove, Phase: "before", Source: "игрок", Target: "коридор", Prevented: true, Message: "нельзя"}
+		}
+		if record.Time.IsZero() || !reflect.DeepEqual(record, expected) {
+			t.Error("\n\tresult:  ", record, "\n\texpected:", expected)
+		}
+	}
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	emitter := NewEventEmitter()
+
+	gate := make(chan struct{})
+	var delivered []string
+	sub := emitter.OnAsync(AnyEvent, func(event *Event) error {
+		<-gate
+		delivered = append(delivered, event.Message)
+		return nil
+	}, AsyncOptions{QueueSize: 1, Policy: BackpressureDropOldest})
+
+	messages := []string{"первое", "второе", "третье"}
+	for _, message := range messages {
+		event := NewEvent(EventAfterMove, nil, nil, nil)
+		event.Message = message
+		if err := emitter.Emit(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(gate)
+	emitter.Close()
+
+	if sub.Dropped() == 0 || len(delivered)+int(sub.Dropped()) != len(messages) {
+		t.Error("delivered:", delivered, "dropped:", sub.Dropped())
+	}
+	if len(delivered) == 0 || delivered[len(delivered)-1] != "третье" {
+		t.Error("newest event must survive:", delivered)
+	}
+}
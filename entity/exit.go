@@ -0,0 +1,105 @@
+package entity
+
+var compassDirections = []string{"север", "юг", "восток", "запад", "вверх", "вниз"}
+
+var oppositeDirections = map[string]string{
+	"север":  "юг",
+	"юг":     "север",
+	"восток": "запад",
+	"запад":  "восток",
+	"вверх":  "вниз",
+	"вниз":   "вверх",
+}
+
+var directionAliases = map[string]string{
+	"с": "север",
+	"ю": "юг",
+	"в": "восток",
+	"з": "запад",
+}
+
+func CompassDirections() []string {
+	return append([]string(nil), compassDirections...)
+}
+
+// ShortDirections возвращает краткие формы стороны света: "с" для "север".
+func ShortDirections(direction string) []string {
+	var short []string
+	for alias, full := range directionAliases {
+		if full == direction {
+			short = append(short, alias)
+		}
+	}
+	return short
+}
+
+// NormalizeDirection приводит краткую форму ("с", "ю") к полной; для прочих слов возвращает false.
+func NormalizeDirection(word string) (string, bool) {
+	if full, ok := directionAliases[word]; ok {
+		return full, true
+	}
+	_, ok := oppositeDirections[word]
+	return word, ok
+}
+
+func OppositeDirection(direction string) (string, bool) {
+	opposite, ok := oppositeDirections[direction]
+	return opposite, ok
+}
+
+// Connect добавляет переход в room; direction может быть пустым, тогда пройти можно только по названию комнаты.
+func (r *Room) Connect(direction string, room *Room) {
+	r.Connections[room.Name] = room
+	if direction == "" {
+		return
+	}
+	if r.Directions == nil {
+		r.Directions = make(map[string]*Room)
+	}
+	r.Directions[direction] = room
+}
+
+// ConnectBoth добавляет переход в обе стороны; обратное направление выводится из direction.
+func (r *Room) ConnectBoth(direction string, room *Room) {
+	r.Connect(direction, room)
+	opposite, _ := OppositeDirection(direction)
+	room.Connect(opposite, r)
+}
+
+// Exit ищет переход по названию комнаты или по стороне света.
+func (r *Room) Exit(name string) (*Room, bool) {
+	if room, ok := r.Connections[name]; ok {
+		return room, true
+	}
+	if direction, ok := NormalizeDirection(name); ok {
+		room, ok := r.Directions[direction]
+		return room, ok
+	}
+	return nil, false
+}
+
+// Lock запирает переход в room, например закрытой дверью; reason увидит игрок.
+func (r *Room) Lock(room *Room, reason string) {
+	if r.Locks == nil {
+		r.Locks = make(map[string]string)
+	}
+	r.Locks[room.Name] = reason
+}
+
+func (r *Room) Unlock(room *Room) {
+	delete(r.Locks, room.Name)
+}
+
+func (r *Room) LockReason(room *Room) (string, bool) {
+	reason, locked := r.Locks[room.Name]
+	return reason, locked
+}
+
+func (r *Room) DirectionTo(room *Room) string {
+	for _, direction := range compassDirections {
+		if r.Directions[direction] == room {
+			return direction
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package entity
+
+type GrammaticalCase int
+
+const (
+	Nominative   GrammaticalCase = iota // что? - шкаф
+	Genitive                            // чего? - шкафа
+	Dative                              // чему? - шкафу
+	Accusative                          // что? - шкаф
+	Instrumental                        // чем? - шкафом
+	Locative                            // где? - в шкафу, на столе
+)
+
+// Furniture - место в комнате, на котором или в котором лежат предметы.
+// Предметы хранятся в Room.Items под ключом Place().
+type Furniture struct {
+	Forms       [Locative + 1]string
+	Preposition string // "на", "в", "под"
+	Description string
+	Capacity    int // 0 - без ограничений
+	Openable    bool
+	Opened      bool
+	Transparent bool // Содержимое видно, даже когда закрыто
+	Emitter     *EventEmitter
+}
+
+type FurniturePayload struct {
+	Furniture *Furniture
+}
+
+// NewFurniture принимает формы названия по падежам, начиная с именительного; недостающие берутся из именительного.
+func NewFurniture(preposition string, forms ...string) *Furniture {
+	f := &Furniture{
+		Preposition: preposition,
+		Emitter:     NewEventEmitter(),
+	}
+	for c := range f.Forms {
+		if c < len(forms) {
+			f.Forms[c] = forms[c]
+		} else if len(forms) > 0 {
+			f.Forms[c] = forms[Nominative]
+		}
+	}
+	return f
+}
+
+func (f *Furniture) Name() string {
+	return f.Forms[Nominative]
+}
+
+func (f *Furniture) Form(c GrammaticalCase) string {
+	if f.Forms[c] == "" {
+		return f.Name()
+	}
+	return f.Forms[c]
+}
+
+func (f *Furniture) Place() string {
+	return f.Form(Locative)
+}
+
+func (f *Furniture) String() string {
+	return f.Name()
+}
+
+// Matches сравнивает название с любой падежной формой.
+func (f *Furniture) Matches(name string) bool {
+	for _, form := range f.Forms {
+		if form != "" && form == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Furniture) ContentsVisible() bool {
+	return !f.Openable || f.Opened || f.Transparent
+}
+
+func (f *Furniture) HasSpace(count int) bool {
+	return f.Capacity == 0 || count < f.Capacity
+}
+
+func (f *Furniture) Open(player *Player) {
+	f.setOpened(player, true, EventFurnitureOpened)
+}
+
+func (f *Furniture) Close(player *Player) {
+	f.setOpened(player, false, EventFurnitureClosed)
+}
+
+func (f *Furniture) setOpened(player *Player, opened bool, eventType EventType) {
+	f.Opened = opened
+	event := NewEvent(eventType, player, f, FurniturePayload{Furniture: f})
+	err := f.Emitter.Emit(event)
+	if err != nil {
+		return
+	}
+}
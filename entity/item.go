@@ -38,12 +38,7 @@ func (i *Item) SetTrait(trait string, value interface{}) {
 }
 
 func (i *Item) Use(target interface{}) string {
-	event := &Event{
-		Type:   EventItemUsed,
-		Source: i,
-		Target: target,
-		Data:   make(map[string]interface{}),
-	}
+	event := NewEvent(EventItemUsed, i, target, UsePayload{Item: i, Target: target})
 	err := i.Emitter.Emit(event)
 	if err != nil {
 		return ""
@@ -52,12 +47,7 @@ func (i *Item) Use(target interface{}) string {
 }
 
 func (i *Item) OnPickup(player *Player) string {
-	event := &Event{
-		Type:   EventItemPicked,
-		Source: i,
-		Target: player,
-		Data:   make(map[string]interface{}),
-	}
+	event := NewEvent(EventItemPicked, i, player, ItemPayload{Item: i})
 	err := i.Emitter.Emit(event)
 	if err != nil {
 		return ""
@@ -65,13 +55,38 @@ func (i *Item) OnPickup(player *Player) string {
 	return fmt.Sprintf("предмет добавлен в инвентарь: %s", i.Name)
 }
 
-func (i *Item) OnDrop(room *Room) string {
-	event := &Event{
-		Type:   EventItemDropped,
-		Source: i,
-		Target: room,
-		Data:   make(map[string]interface{}),
+// CanPickup спрашивает обработчиков before_pickup, можно ли взять предмет.
+func (i *Item) CanPickup(player *Player, place string) (string, bool) {
+	event := NewBeforeEvent(EventBeforePickup, i, player, ItemPayload{Item: i, Place: place})
+	err := i.Emitter.Emit(event)
+	if err != nil {
+		return "", false
+	}
+	if event.Prevented {
+		return event.Message, false
 	}
+	return "", true
+}
+
+func (i *Item) OnWear(player *Player) string {
+	event := NewEvent(EventItemWorn, i, player, ItemPayload{Item: i})
+	err := i.Emitter.Emit(event)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("вы надели: %s", i.Name)
+}
+
+func (i *Item) OnConsume(player *Player) {
+	event := NewEvent(EventItemConsumed, i, player, ItemPayload{Item: i})
+	err := i.Emitter.Emit(event)
+	if err != nil {
+		return
+	}
+}
+
+func (i *Item) OnDrop(room *Room) string {
+	event := NewEvent(EventItemDropped, i, room, ItemPayload{Item: i})
 	err := i.Emitter.Emit(event)
 	if err != nil {
 		return ""
@@ -79,6 +94,30 @@ func (i *Item) OnDrop(room *Room) string {
 	return fmt.Sprintf("предмет выброшен: %s", i.Name)
 }
 
+func (i *Item) Consumable() *Consumable {
+	consumable, _ := i.GetTrait("consumable").(*Consumable)
+	return consumable
+}
+
 func (i *Item) IsWearable() bool {
 	return i.HasTrait("wearable")
 }
+
+// IsHidden - предмет лежит на месте, но игрок его ещё не нашёл.
+func (i *Item) IsHidden() bool {
+	hidden, _ := i.GetTrait("hidden").(bool)
+	return hidden
+}
+
+func (i *Item) Hide() {
+	i.SetTrait("hidden", true)
+}
+
+func (i *Item) Reveal(player *Player) {
+	delete(i.Traits, "hidden")
+	event := NewEvent(EventItemRevealed, player, i, ItemPayload{Item: i})
+	err := i.Emitter.Emit(event)
+	if err != nil {
+		return
+	}
+}
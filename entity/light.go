@@ -0,0 +1,52 @@
+package entity
+
+type LightSource struct {
+	Lit  bool
+	Fuel int // Оставшиеся ходы горения, меньше нуля - не кончается
+}
+
+func (i *Item) LightSource() *LightSource {
+	light, _ := i.GetTrait("light").(*LightSource)
+	return light
+}
+
+func (l *LightSource) HasFuel() bool {
+	return l.Fuel != 0
+}
+
+// Burn тратит ход горения; возвращает true, если источник только что погас.
+func (l *LightSource) Burn() bool {
+	if !l.Lit || l.Fuel < 0 {
+		return false
+	}
+	if l.Fuel > 0 {
+		l.Fuel--
+	}
+	if l.Fuel == 0 {
+		l.Lit = false
+		return true
+	}
+	return false
+}
+
+// OnLightOut сообщает, что источник света догорел.
+func (i *Item) OnLightOut(holder interface{}) {
+	_ = i.Emitter.Emit(NewEvent(EventLightOut, i, holder, ItemPayload{Item: i}))
+}
+
+func (r *Room) IsDark() bool {
+	dark, _ := r.GetTrait("dark").(bool)
+	return dark
+}
+
+// HasLight - у игрока с собой горящий источник света.
+func (p *Player) HasLight() bool {
+	for _, items := range [][]*Item{p.Inventory, p.WornItems} {
+		for _, item := range items {
+			if light := item.LightSource(); light != nil && light.Lit {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -6,24 +6,67 @@ type Player struct {
 	CurrentRoom *Room
 	Inventory   []*Item
 	WornItems   []*Item
+	Attributes  map[string]int
+	Effects     []*StatusEffect
+	Visits      map[string]int
+	Verbosity   Verbosity
+	Compass     bool // Показывать выходы со сторонами света
 	Emitter     *EventEmitter
 }
 
+type Verbosity int
+
+const (
+	VerbosityFull  Verbosity = iota // Полные описания, при повторном входе - RevisitMessage
+	VerbosityBrief                  // При повторном входе - BriefMessage или название комнаты
+
+	MsgMoveFailed = "не получается пройти" // Обработчик before_move вернул ошибку, переход не состоялся
+)
+
 func NewPlayer(startRoom *Room) *Player {
 	return &Player{
 		CurrentRoom: startRoom,
 		Inventory:   make([]*Item, 0),
 		WornItems:   make([]*Item, 0),
-		Emitter:     NewEventEmitter(),
+		Attributes: map[string]int{
+			AttrHealth: AttrMax,
+			AttrEnergy: 60,
+			AttrHunger: 30,
+		},
+		Effects: make([]*StatusEffect, 0),
+		Visits:  map[string]int{startRoom.Name: 1},
+		Emitter: NewEventEmitter(),
 	}
 }
 
-func (p *Player) Move(room *Room) string {
-	if p.CurrentRoom != nil {
-		p.CurrentRoom.OnExit(p)
+func (p *Player) HasVisited(room *Room) bool {
+	return p.Visits[room.Name] > 0
+}
+
+// Move возвращает false и причину, если переход запретил обработчик before_move.
+// room_exited, room_entered и after_move - информационные события уже совершённого перехода.
+func (p *Player) Move(room *Room, direction string) (string, bool) {
+	payload := MovePayload{From: p.CurrentRoom, To: room, Direction: direction}
+
+	before := NewBeforeEvent(EventBeforeMove, p, room, payload)
+	if err := p.Emitter.Emit(before); err != nil {
+		return MsgMoveFailed, false
 	}
+	if before.Prevented {
+		return before.Message, false
+	}
+
 	p.CurrentRoom = room
-	return room.OnEnter(p)
+	p.Visits[room.Name]++
+	room.WasVisited = true
+
+	if payload.From != nil {
+		payload.From.OnExit(p, payload)
+	}
+	answer := room.OnEnter(p, payload)
+
+	_ = p.Emitter.Emit(NewEvent(EventAfterMove, p, room, payload))
+	return answer, true
 }
 
 func (p *Player) HasBackpack() bool {
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AgDecode/mini-game/game"
+)
+
+const maxAnswerLen = 1024
+
+// go test -fuzz FuzzHandleCommand -run '^$'
+func FuzzHandleCommand(f *testing.F) {
+	for _, commands := range game0cases {
+		lines := make([]string, len(commands))
+		for i, item := range commands {
+			lines[i] = item.command
+			f.Add(item.command)
+		}
+		f.Add(strings.Join(lines, "\n"))
+	}
+	f.Add("идти\xff\xfe коридор")
+	f.Add("  \t\n\x00")
+	f.Add("а = б; б\nб = а; а\nа")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		initGame()
+
+		for _, command := range strings.Split(input, "\n") {
+			answer := handleCommand(command)
+			if answer == "" {
+				t.Fatalf("empty answer for %q", command)
+			}
+			if len(answer) > maxAnswerLen*game.MaxLineCommands+len(command) { // одна строка может запустить несколько команд
+				t.Fatalf("answer for %q is %d bytes long", command, len(answer))
+			}
+			if err := game.CheckInvariants(gameState); err != nil {
+				t.Fatalf("after %q: %v", command, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	CmdAlias   = "алиас"
+	CmdAliases = "алиасы"
+
+	// MaxLineCommands ограничивает число команд, которые может породить одна строка с макросами.
+	MaxLineCommands = 10
+	maxAliasDepth   = 5
+
+	commandSeparator = ";"
+	aliasAssign      = "="
+
+	MsgAliasSet      = "алиас задан: %s = %s"
+	MsgAliasRemoved  = "алиас удалён: %s"
+	MsgAliasBuiltin  = "нельзя переопределить команду %s"
+	MsgAliasBadName  = "неправильное имя алиаса"
+	MsgAliasLoop     = "алиас %s вызывает сам себя"
+	MsgTooManySteps  = "слишком много команд за раз"
+	MsgNoAliases     = "алиасов нет"
+	MsgAliasesList   = "алиасы: %s"
+	MsgAliasUsage    = "алиас <имя> = <команды через ;>"
+	aliasReplyJoiner = "\n"
+)
+
+// expansion отслеживает раскрытие одной строки: цепочку алиасов и число выполненных команд.
+type expansion struct {
+	chain    []string
+	commands int
+}
+
+// SetAlias задаёт алиас или макрос; пустое определение удаляет его. Алиасы сохраняются в профиль.
+// Алиас может перекрыть встроенную команду: внутри своего раскрытия имя снова означает команду.
+func (s *State) SetAlias(name, definition string) string {
+	if name == "" || strings.ContainsAny(name, commandSeparator+aliasAssign) || len(strings.Fields(name)) != 1 {
+		return s.fail(CodeBadAlias, MsgAliasBadName)
+	}
+	if name == CmdAlias || name == CmdAliases {
+		return s.fail(CodeBadAlias, fmt.Sprintf(MsgAliasBuiltin, name))
+	}
+
+	definition = strings.TrimSpace(definition)
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]string)
+	}
+
+	answer := fmt.Sprintf(MsgAliasSet, name, definition)
+	if definition == "" {
+		delete(s.Aliases, name)
+		answer = fmt.Sprintf(MsgAliasRemoved, name)
+	} else {
+		s.Aliases[name] = definition
+	}
+
+	if s.Profile != nil {
+		s.Profile.Aliases = s.Aliases
+		err := s.saveProfile()
+		if err != nil {
+			return answer
+		}
+	}
+	return answer
+}
+
+func (s *State) handleAliases() string {
+	if len(s.Aliases) == 0 {
+		return MsgNoAliases
+	}
+
+	names := make([]string, 0, len(s.Aliases))
+	for name := range s.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + " " + aliasAssign + " " + s.Aliases[name]
+	}
+	return fmt.Sprintf(MsgAliasesList, strings.Join(parts, ", "))
+}
+
+// parseAliasDefinition распознаёт "алиас в = идти" и "утро = идти комната; надеть рюкзак".
+func parseAliasDefinition(line string) (string, string, bool) {
+	left, right, found := strings.Cut(line, aliasAssign)
+	if !found {
+		return "", "", false
+	}
+
+	fields := strings.Fields(left)
+	if len(fields) > 0 && fields[0] == CmdAlias {
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return "", "", false
+	}
+	return fields[0], right, true
+}
+
+// splitAliasDefinition отделяет определение алиаса от команд перед ним: "взять ключи; в = идти".
+// Определение забирает остаток строки, поэтому макрос может содержать ";".
+func splitAliasDefinition(line string) (string, string, string, bool) {
+	segments := strings.Split(line, commandSeparator)
+	for i, segment := range segments {
+		if !strings.Contains(segment, aliasAssign) {
+			continue
+		}
+		name, definition, ok := parseAliasDefinition(strings.Join(segments[i:], commandSeparator))
+		if !ok {
+			return "", "", "", false
+		}
+		return strings.Join(segments[:i], commandSeparator), name, definition, true
+	}
+	return "", "", "", false
+}
+
+// runLine выполняет команды, разделённые ";", и склеивает ответы.
+func (s *State) runLine(line string, exp *expansion) string {
+	var answers []string
+	for _, command := range strings.Split(line, commandSeparator) {
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+		answers = append(answers, s.runCommand(command, exp))
+		if exp.commands > MaxLineCommands {
+			break
+		}
+	}
+
+	if len(answers) == 0 {
+		return s.fail(CodeUnknownCommand, MsgUnknownCommand)
+	}
+	return strings.Join(answers, aliasReplyJoiner)
+}
+
+func (s *State) runCommand(command string, exp *expansion) string {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return s.fail(CodeUnknownCommand, MsgUnknownCommand)
+	}
+
+	name := parts[0]
+	if definition, ok := s.Aliases[name]; ok && !exp.expanding(name) {
+		if len(exp.chain) >= maxAliasDepth {
+			return s.countStep(exp, s.fail(CodeBadAlias, fmt.Sprintf(MsgAliasLoop, name)))
+		}
+
+		expanded := strings.Join(append([]string{definition}, parts[1:]...), " ")
+		exp.chain = append(exp.chain, name)
+		answer := s.runLine(expanded, exp)
+		exp.chain = exp.chain[:len(exp.chain)-1]
+		return answer
+	}
+
+	if _, builtin := s.Commands[name]; !builtin && exp.expanding(name) {
+		return s.countStep(exp, s.fail(CodeBadAlias, fmt.Sprintf(MsgAliasLoop, name)))
+	}
+
+	exp.commands++
+	if exp.commands > MaxLineCommands {
+		return s.fail(CodeTooManySteps, MsgTooManySteps)
+	}
+	return s.dispatch(name, parts[1:])
+}
+
+func (e *expansion) expanding(name string) bool {
+	for _, expanded := range e.chain {
+		if expanded == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countStep засчитывает ответ без выполнения команды, чтобы зацикленные макросы не раздували ответ.
+func (s *State) countStep(exp *expansion, answer string) string {
+	exp.commands++
+	if exp.commands > MaxLineCommands {
+		return s.fail(CodeTooManySteps, MsgTooManySteps)
+	}
+	return answer
+}
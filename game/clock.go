@@ -0,0 +1,158 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	MsgTime           = "сейчас %s, %s"
+	MsgUniversityShut = "универ уже закрыт"
+)
+
+var (
+	DefaultStartTime  = time.Date(2024, time.April, 1, 8, 0, 0, 0, time.UTC)
+	DefaultTurnLength = time.Minute
+)
+
+type Clock interface {
+	Now() time.Time
+	Advance()
+	Reset() // Вернуть время к началу игры
+}
+
+// TurnClock сдвигает время на фиксированный шаг после каждой команды.
+type TurnClock struct {
+	start   time.Time
+	current time.Time
+	step    time.Duration
+}
+
+func NewTurnClock(start time.Time, step time.Duration) *TurnClock {
+	return &TurnClock{
+		start:   start,
+		current: start,
+		step:    step,
+	}
+}
+
+func (c *TurnClock) Now() time.Time {
+	return c.current
+}
+
+func (c *TurnClock) Advance() {
+	c.current = c.current.Add(c.step)
+}
+
+func (c *TurnClock) Reset() {
+	c.current = c.start
+}
+
+type ScheduledEvent struct {
+	At     time.Time
+	Name   string
+	Action func(*State)
+	hour   int
+	minute int
+	daily  bool // Время задано часами и минутами, SetClock переносит событие на день новых часов
+}
+
+type Scheduler struct {
+	events []*ScheduledEvent
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		events: make([]*ScheduledEvent, 0),
+	}
+}
+
+func (sc *Scheduler) Schedule(at time.Time, name string, action func(*State)) {
+	sc.add(&ScheduledEvent{At: at, Name: name, Action: action})
+}
+
+func (sc *Scheduler) add(event *ScheduledEvent) {
+	sc.events = append(sc.events, event)
+	sc.sort()
+}
+
+func (sc *Scheduler) sort() {
+	sort.SliceStable(sc.events, func(i, j int) bool {
+		return sc.events[i].At.Before(sc.events[j].At)
+	})
+}
+
+func (sc *Scheduler) Due(now time.Time) []*ScheduledEvent {
+	n := 0
+	for n < len(sc.events) && !sc.events[n].At.After(now) {
+		n++
+	}
+	due := sc.events[:n:n]
+	sc.events = sc.events[n:]
+	return due
+}
+
+func (sc *Scheduler) Pending() []*ScheduledEvent {
+	return sc.events
+}
+
+// ScheduleAt ставит событие на hour:minute текущего игрового дня.
+func (s *State) ScheduleAt(hour, minute int, name string, action func(*State)) {
+	s.Scheduler.add(&ScheduledEvent{At: s.At(hour, minute), Name: name, Action: action, hour: hour, minute: minute, daily: true})
+}
+
+// SetClock меняет часы и переносит ещё не сработавшие события ScheduleAt на их время.
+func (s *State) SetClock(clock Clock) {
+	s.Clock = clock
+	for _, event := range s.Scheduler.Pending() {
+		if event.daily {
+			event.At = s.At(event.hour, event.minute)
+		}
+	}
+	s.Scheduler.sort()
+}
+
+func (s *State) Now() time.Time {
+	return s.Clock.Now()
+}
+
+func (s *State) At(hour, minute int) time.Time {
+	now := s.Clock.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+}
+
+func (s *State) TimeOfDay() string {
+	return timeOfDay(s.Clock.Now())
+}
+
+func timeOfDay(t time.Time) string {
+	switch hour := t.Hour(); {
+	case hour >= 5 && hour < 12:
+		return "утро"
+	case hour >= 12 && hour < 18:
+		return "день"
+	case hour >= 18 && hour < 23:
+		return "вечер"
+	default:
+		return "ночь"
+	}
+}
+
+func (s *State) tick() {
+	s.Turn++
+	s.Clock.Advance()
+	s.tickPlayer()
+	s.tickLights()
+	for _, event := range s.Scheduler.Due(s.Clock.Now()) {
+		event.Action(s)
+	}
+}
+
+func (s *State) handleTime() string {
+	answer := fmt.Sprintf(MsgTime, s.Clock.Now().Format("15:04"), s.TimeOfDay())
+	if s.UniversityClosed {
+		answer += ", " + MsgUniversityShut
+	}
+	return answer
+}
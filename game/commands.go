@@ -2,8 +2,9 @@ package game
 
 import (
 	"fmt"
-	"github.com/AgDecode/mini-game/entity"
 	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
 )
 
 const (
@@ -18,165 +19,169 @@ const (
 	MsgCannotWear        = "нельзя надеть"
 	MsgDoorClosed        = "дверь закрыта"
 	MsgNoPath            = "нет пути в %s"
+	MsgNoExit            = "в ту сторону не пройти"
 	MsgNoItemInInventory = "нет предмета в инвентаре - %s"
 	MsgNothingToApply    = "не к чему применить"
+	MsgVerbosityFull     = "режим описаний: подробно"
+	MsgVerbosityBrief    = "режим описаний: кратко"
+	MsgCompassOn         = "выходы: со сторонами света"
+	MsgCompassOff        = "выходы: по названиям"
 )
 
+// HandleCommand возвращает только текст ответа; статус и изменения мира - в Execute.
 func (s *State) HandleCommand(command string) string {
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		return MsgUnknownCommand
-	}
-
-	cmd := parts[0]
-	args := parts[1:]
-
-	if handler, exists := s.Commands[cmd]; exists {
-		return handler(s, args)
-	}
-
-	return MsgUnknownCommand
+	return s.Execute(command).Text
 }
 
-func (s *State) handleLook() string {
-	room := s.Player.CurrentRoom
-	parts := []string{}
-
-	if room.Name == "кухня" {
-		parts = append(parts, "ты находишься на кухне")
-	}
-
-	itemParts := s.getItemDescriptionParts(room)
-	if len(itemParts) > 0 {
-		parts = append(parts, strings.Join(itemParts, ", "))
-	} else if room.Name != "кухня" {
-		parts = append(parts, "пустая комната")
+func (s *State) handleCommand(command string) string {
+	if commands, name, definition, ok := splitAliasDefinition(command); ok {
+		if strings.TrimSpace(commands) == "" {
+			return s.defineAlias(name, definition)
+		}
+		answer := s.runLine(commands, &expansion{})
+		return answer + aliasReplyJoiner + s.defineAlias(name, definition)
 	}
-
-	s.addHintsToParts(room, &parts)
-
-	s.addExitsToParts(room, &parts)
-
-	return s.formatRoomDescription(room, parts)
+	return s.runLine(command, &expansion{})
 }
 
-func (s *State) getItemDescriptionParts(room *entity.Room) []string {
-	var itemParts []string
-
-	if items, ok := room.Items["столе"]; ok && len(items) > 0 {
-		itemNames := s.getItemNames(items)
-		itemParts = append(itemParts, fmt.Sprintf("на столе: %s", strings.Join(itemNames, ", ")))
+// defineAlias задаёт алиас из строки "имя = команды"; после конца игры, как и любая команда, отказывает.
+func (s *State) defineAlias(name, definition string) string {
+	if s.GameOver {
+		return s.fail(CodeGameOver, s.gameOverSummary())
 	}
-
-	if items, ok := room.Items["стуле"]; ok && len(items) > 0 {
-		itemNames := s.getItemNames(items)
-		itemParts = append(itemParts, fmt.Sprintf("на стуле: %s", strings.Join(itemNames, ", ")))
-	}
-
-	return itemParts
+	return s.SetAlias(name, definition)
 }
 
-func (s *State) getItemNames(items []*entity.Item) []string {
-	itemNames := make([]string, len(items))
-	for i, item := range items {
-		itemNames[i] = item.Name
+func (s *State) dispatch(cmd string, args []string) string {
+	if s.GameOver && cmd != CmdRestart {
+		return s.fail(CodeGameOver, s.gameOverSummary())
 	}
-	return itemNames
-}
 
-func (s *State) addHintsToParts(room *entity.Room, parts *[]string) {
-	if room.HasHint {
-		if room.Name == "кухня" {
-			if s.Player.HasBackpack() {
-				*parts = append(*parts, "надо идти в универ")
-			} else {
-				*parts = append(*parts, "надо собрать рюкзак и идти в универ")
-			}
+	if handler, exists := s.Commands[cmd]; exists {
+		answer := handler(s, args)
+		if cmd != CmdRestart {
+			s.tick()
+			s.checkTerminalConditions()
 		}
-	}
-}
-
-func (s *State) addExitsToParts(room *entity.Room, parts *[]string) {
-	if len(room.Connections) > 0 {
-		directions := make([]string, 0, len(room.Connections))
-		for dir := range room.Connections {
-			directions = append(directions, dir)
+		if len(s.notices) > 0 {
+			answer += ". " + strings.Join(s.notices, ". ")
+			s.notices = nil
 		}
-		*parts = append(*parts, fmt.Sprintf("можно пройти - %s", strings.Join(directions, ", ")))
+		return answer
 	}
+
+	return s.fail(CodeUnknownCommand, s.unknownCommand(cmd))
 }
 
-func (s *State) formatRoomDescription(room *entity.Room, parts []string) string {
-	if room.Name == "кухня" {
-		return strings.Join(parts[:len(parts)-1], ", ") + ". " + parts[len(parts)-1]
+func (s *State) handleLook() string {
+	room := s.Player.CurrentRoom
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+	if message, ok := room.TimeMessages[s.TimeOfDay()]; ok {
+		return message
 	}
-	return strings.Join(parts, ". ")
+	return s.renderLook(room)
 }
 
 func (s *State) handleGo(direction string) string {
 	room := s.Player.CurrentRoom
-	nextRoom, exists := room.Connections[direction]
+	nextRoom, exists := room.Exit(direction)
 
 	if !exists {
-		return fmt.Sprintf(MsgNoPath, direction)
-	}
-
-	if nextRoom.Name == "улица" && !s.DoorOpened {
-		return MsgDoorClosed
+		if _, compass := entity.NormalizeDirection(direction); compass {
+			return s.fail(CodeNoPath, MsgNoExit)
+		}
+		return s.fail(CodeNoPath, fmt.Sprintf(MsgNoPath, direction))
 	}
 
-	s.Player.CurrentRoom = nextRoom
-	s.LastCommand = "идти"
+	revisit := s.Player.HasVisited(nextRoom)
 
-	s.emitEnterRoomEvent(nextRoom)
+	// В событие попадает полная сторона света, как бы игрок её ни назвал: "ю", "юг" или "улица".
+	if message, ok := s.Player.Move(nextRoom, room.DirectionTo(nextRoom)); !ok {
+		return s.block(message)
+	}
 
-	return s.getRoomEnterMessage(nextRoom)
+	return s.getRoomEnterMessage(nextRoom, revisit)
 }
 
-func (s *State) emitEnterRoomEvent(room *entity.Room) {
-	event := &entity.Event{
-		Type:   "enter_room",
-		Source: s.Player,
-		Target: room,
-		Data:   make(map[string]interface{}),
+func (s *State) getRoomEnterMessage(room *entity.Room, revisit bool) string {
+	if !s.CanSee(room) {
+		return MsgTooDark
 	}
-	err := s.EventEmitter.Emit(event)
-	if err != nil {
-		return
+	if revisit && s.Player.Verbosity == entity.VerbosityBrief {
+		if room.BriefMessage != "" {
+			return room.BriefMessage
+		}
+		return room.Name
+	}
+	if message, ok := room.TimeMessages[s.TimeOfDay()]; ok {
+		return message
+	}
+	if revisit && room.RevisitMessage != "" {
+		return room.RevisitMessage
 	}
-}
-
-func (s *State) getRoomEnterMessage(room *entity.Room) string {
 	if room.EnterMessage != "" {
 		return room.EnterMessage
 	}
 	return s.handleLook()
 }
 
+func (s *State) handleVerbosity(verbosity entity.Verbosity) string {
+	s.Player.Verbosity = verbosity
+	if verbosity == entity.VerbosityBrief {
+		return MsgVerbosityBrief
+	}
+	return MsgVerbosityFull
+}
+
+func (s *State) handleCompass() string {
+	s.Player.Compass = !s.Player.Compass
+	if s.Player.Compass {
+		return MsgCompassOn
+	}
+	return MsgCompassOff
+}
+
 func (s *State) handleTake(itemName string) string {
 	room := s.Player.CurrentRoom
 
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+
 	item, place := s.findItemWithLocation(room, itemName)
 	if item == nil {
-		return MsgItemNotFound
+		return s.fail(CodeNotFound, MsgItemNotFound)
 	}
 
 	if !s.Player.HasBackpack() && item.Name != "рюкзак" {
-		return MsgNoBackpack
+		return s.fail(CodeNoBackpack, MsgNoBackpack)
+	}
+
+	if message, ok := item.CanPickup(s.Player, place); !ok {
+		return s.block(message)
 	}
 
 	s.removeItemFromRoom(room, item, place)
 
 	s.Player.Inventory = append(s.Player.Inventory, item)
+	s.itemMoved(item, place, LocationInventory)
 
 	s.updateRoomDescriptionIfEmpty(room)
 
+	item.OnPickup(s.Player)
+
 	return fmt.Sprintf(MsgItemAdded, itemName)
 }
 
+// findItemWithLocation ищет только среди видимых предметов: темнота, закрытая мебель и тайники прячут своё содержимое.
 func (s *State) findItemWithLocation(room *entity.Room, itemName string) (*entity.Item, string) {
-	for place, items := range room.Items {
-		for _, item := range items {
+	if !s.CanSee(room) {
+		return nil, ""
+	}
+	for place := range room.Items {
+		for _, item := range room.VisibleItemsAt(place) {
 			if item.Name == itemName {
 				return item, place
 			}
@@ -186,14 +191,8 @@ func (s *State) findItemWithLocation(room *entity.Room, itemName string) (*entit
 }
 
 func (s *State) findItemInRoom(room *entity.Room, itemName string) *entity.Item {
-	for _, items := range room.Items {
-		for _, item := range items {
-			if item.Name == itemName {
-				return item
-			}
-		}
-	}
-	return nil
+	item, _ := s.findItemWithLocation(room, itemName)
+	return item
 }
 
 func (s *State) removeItemFromRoom(room *entity.Room, item *entity.Item, place string) {
@@ -223,21 +222,30 @@ func (s *State) handleWear(itemName string) string {
 
 	item, place, inRoom := s.findItemForWearing(room, itemName)
 	if item == nil {
-		return MsgItemNotFound
+		return s.fail(CodeNotFound, MsgItemNotFound)
 	}
 
 	if item.Name != "рюкзак" && !item.HasTrait("wearable") {
-		return MsgCannotWear
+		return s.fail(CodeNotApplicable, MsgCannotWear)
+	}
+
+	if inRoom {
+		if message, ok := item.CanPickup(s.Player, place); !ok {
+			return s.block(message)
+		}
 	}
 
 	s.removeItemForWearing(room, item, place, inRoom)
 
 	s.Player.WornItems = append(s.Player.WornItems, item)
+	s.itemMoved(item, itemLocation(place, inRoom), LocationWorn)
 
 	if inRoom {
 		s.updateRoomDescriptionIfEmpty(room)
 	}
 
+	item.OnWear(s.Player)
+
 	return fmt.Sprintf(MsgWearing, itemName)
 }
 
@@ -256,6 +264,14 @@ func (s *State) findItemForWearing(room *entity.Room, itemName string) (*entity.
 	return nil, "", false
 }
 
+// itemLocation - место предмета для Delta: место в комнате или рюкзак.
+func itemLocation(place string, inRoom bool) string {
+	if inRoom {
+		return place
+	}
+	return LocationInventory
+}
+
 func (s *State) removeItemForWearing(room *entity.Room, item *entity.Item, place string, inRoom bool) {
 	if inRoom {
 		for i, it := range room.Items[place] {
@@ -277,7 +293,7 @@ func (s *State) removeItemForWearing(room *entity.Room, item *entity.Item, place
 func (s *State) handleUse(itemName, targetName string) string {
 	item := s.findItemInInventory(itemName)
 	if item == nil {
-		return fmt.Sprintf(MsgNoItemInInventory, itemName)
+		return s.fail(CodeNotInInventory, fmt.Sprintf(MsgNoItemInInventory, itemName))
 	}
 
 	var target *entity.Item
@@ -289,7 +305,7 @@ func (s *State) handleUse(itemName, targetName string) string {
 	}
 
 	if target == nil {
-		return MsgNothingToApply
+		return s.fail(CodeNotFound, MsgNothingToApply)
 	}
 
 	return s.ApplyInteraction(item, target)
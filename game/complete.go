@@ -0,0 +1,161 @@
+package game
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+// Complete предлагает варианты продолжения строки: сначала команды, затем аргументы по их ArgSpec.
+// Варианты - строки целиком, их можно сразу подставлять вместо введённого.
+func (s *State) Complete(prefix string) []string {
+	done := ""
+	if i := strings.LastIndex(prefix, commandSeparator); i >= 0 {
+		done, prefix = prefix[:i+1]+" ", strings.TrimLeft(prefix[i+1:], " ")
+	}
+
+	words := strings.Fields(prefix)
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(prefix, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var candidates []string
+	if len(words) == 0 {
+		candidates = s.completeVerbs()
+	} else {
+		candidates = s.completeArg(words[0], words[1:])
+	}
+
+	head := done
+	if len(words) > 0 {
+		head += strings.Join(words, " ") + " "
+	}
+
+	seen := map[string]bool{}
+	var lines []string
+	for _, candidate := range candidates {
+		if !strings.HasPrefix(candidate, current) || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		lines = append(lines, head+candidate)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func (s *State) completeVerbs() []string {
+	verbs := make([]string, 0, len(s.Commands)+len(s.Aliases))
+	for name := range s.Commands {
+		verbs = append(verbs, name)
+	}
+	for name := range s.Aliases {
+		verbs = append(verbs, name)
+	}
+	return verbs
+}
+
+// completeArg подбирает значения для очередного аргумента команды verb.
+func (s *State) completeArg(verb string, args []string) []string {
+	info := s.CommandInfo(verb)
+	if info == nil {
+		return nil
+	}
+	room := s.Player.CurrentRoom
+	if len(args) == 1 && args[0] == "до" && len(info.Args) > 0 && info.Args[0].Kind == ArgExit {
+		if !s.CanSee(room) {
+			return nil
+		}
+		return s.knownRoomNames()
+	}
+	if len(args) >= len(info.Args) {
+		return nil
+	}
+
+	var items []string
+	switch info.Args[len(args)].Kind {
+	case ArgExit:
+		// В темноте идти некуда, подсказывать выходы тоже нечего
+		if !s.CanSee(room) {
+			return nil
+		}
+		exits := append(sortedDirections(room), "до")
+		for direction := range room.Directions {
+			exits = append(exits, direction)
+		}
+		return exits
+	case ArgRoomItem:
+		items = s.visibleItemNames()
+	case ArgInventoryItem:
+		items = s.inventoryItemNames()
+	case ArgLight:
+		for _, item := range append(slices.Clone(s.Player.Inventory), s.Player.WornItems...) {
+			if item.LightSource() != nil {
+				items = append(items, item.Name)
+			}
+		}
+	case ArgItem:
+		items = append(s.visibleItemNames(), s.inventoryItemNames()...)
+	case ArgWearable:
+		for _, name := range append(s.visibleItemNames(), s.inventoryItemNames()...) {
+			if item := s.findItemForCompletion(name); item != nil && item.IsWearable() {
+				items = append(items, name)
+			}
+		}
+	case ArgFurniture:
+		items = s.furnitureNames(false)
+	case ArgOpenable:
+		items = s.furnitureNames(true)
+	case ArgFurnitureOrItem:
+		items = append(s.furnitureNames(false), s.visibleItemNames()...)
+	case ArgCommand:
+		items = s.VisibleCommands()
+	}
+	return items
+}
+
+func (s *State) visibleItemNames() []string {
+	room := s.Player.CurrentRoom
+	if !s.CanSee(room) {
+		return nil
+	}
+	return s.getItemNames(room.VisibleItems())
+}
+
+// inventoryItemNames - только предметы в рюкзаке: надетое команды с предметом в руках не принимают.
+func (s *State) inventoryItemNames() []string {
+	return s.getItemNames(s.Player.Inventory)
+}
+
+func (s *State) furnitureNames(openable bool) []string {
+	room := s.Player.CurrentRoom
+	if !s.CanSee(room) {
+		return nil
+	}
+	var names []string
+	for _, place := range room.Places {
+		if furniture := room.Furniture[place]; furniture != nil && (!openable || furniture.Openable) {
+			names = append(names, furniture.Name())
+		}
+	}
+	return names
+}
+
+func (s *State) knownRoomNames() []string {
+	var names []string
+	for room := range s.knownRooms() {
+		names = append(names, room.Name)
+	}
+	return names
+}
+
+func (s *State) findItemForCompletion(name string) *entity.Item {
+	if item := s.findItemInRoom(s.Player.CurrentRoom, name); item != nil {
+		return item
+	}
+	return s.findItemInInventory(name)
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+// DefaultLookTemplate используется для комнат без собственного LookTemplate.
+const DefaultLookTemplate = `{{if .Places}}{{range $i, $p := .Places}}{{if $i}}, {{end}}{{$p.Preposition}} {{$p.Name}}: {{join $p.Items ", "}}{{end}}{{else}}пустая комната{{end}}` +
+	`{{range .Hints}}. {{.}}{{end}}{{if .Exits}}. можно пройти - {{join .Exits ", "}}{{end}}`
+
+var lookFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+type PlaceView struct {
+	Name        string
+	Preposition string
+	Items       []string
+}
+
+type LookView struct {
+	Room      *entity.Room
+	Player    *entity.Player
+	Places    []PlaceView
+	Hints     []string
+	Exits     []string
+	TimeOfDay string
+}
+
+func (s *State) lookView(room *entity.Room) LookView {
+	view := LookView{
+		Room:      room,
+		Player:    s.Player,
+		Exits:     s.exitNames(room),
+		TimeOfDay: s.TimeOfDay(),
+	}
+
+	for _, place := range room.Places {
+		if items := room.VisibleItemsAt(place); len(items) > 0 {
+			view.Places = append(view.Places, PlaceView{Name: place, Preposition: placePreposition(room, place), Items: s.getItemNames(items)})
+		}
+	}
+
+	if room.HasHint {
+		if hint := s.QuestHint(); hint != "" {
+			view.Hints = append(view.Hints, hint)
+		}
+	}
+	if s.Player.HasEffect(EffectHungry) {
+		view.Hints = append(view.Hints, MsgHungryHint)
+	}
+
+	return view
+}
+
+func (s *State) renderLook(room *entity.Room) string {
+	text := room.LookTemplate
+	if text == "" {
+		text = DefaultLookTemplate
+	}
+
+	tmpl, err := s.lookTemplate(text)
+	if err != nil {
+		tmpl, _ = s.lookTemplate(DefaultLookTemplate)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, s.lookView(room)); err != nil {
+		return room.Description
+	}
+	return out.String()
+}
+
+func (s *State) lookTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := s.templates[text]; ok {
+		return tmpl, nil
+	}
+	tmpl, err := template.New("look").Funcs(lookFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if s.templates == nil {
+		s.templates = make(map[string]*template.Template)
+	}
+	s.templates[text] = tmpl
+	return tmpl, nil
+}
+
+// exitNames перечисляет выходы по названиям комнат, в режиме "компас" - ещё и со сторонами света.
+func (s *State) exitNames(room *entity.Room) []string {
+	exits := sortedDirections(room)
+	if !s.Player.Compass {
+		return exits
+	}
+	for i, name := range exits {
+		if direction := room.DirectionTo(room.Connections[name]); direction != "" {
+			exits[i] = fmt.Sprintf("%s (%s)", name, direction)
+		}
+	}
+	return exits
+}
+
+func placePreposition(room *entity.Room, place string) string {
+	if furniture := room.Furniture[place]; furniture != nil && furniture.Preposition != "" {
+		return furniture.Preposition
+	}
+	return "на"
+}
+
+func (s *State) getItemNames(items []*entity.Item) []string {
+	itemNames := make([]string, len(items))
+	for i, item := range items {
+		itemNames[i] = item.Name
+	}
+	return itemNames
+}
+
+func sortedDirections(room *entity.Room) []string {
+	directions := make([]string, 0, len(room.Connections))
+	for direction := range room.Connections {
+		directions = append(directions, direction)
+	}
+	sort.Strings(directions)
+	return directions
+}
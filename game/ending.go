@@ -0,0 +1,130 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	CmdRestart = "заново"
+
+	MsgGameOver      = "игра окончена: %s. ходов: %d, очков: %d, предметы: %s. чтобы начать сначала - " + CmdRestart
+	MsgNoItemsPicked = "нет"
+	MsgRestarted     = "игра начата заново"
+)
+
+type Outcome int
+
+const (
+	OutcomeNone Outcome = iota
+	OutcomeWon
+	OutcomeLost
+)
+
+type TerminalCondition struct {
+	Name    string
+	Outcome Outcome
+	Message string
+	Check   func(*State) bool
+}
+
+func TurnLimit(limit int, message string) TerminalCondition {
+	return TerminalCondition{
+		Name:    "turn_limit",
+		Outcome: OutcomeLost,
+		Message: message,
+		Check: func(s *State) bool {
+			return s.Turn >= limit
+		},
+	}
+}
+
+func PlayerCondition(outcome Outcome, message string, check func(*entity.Player) bool) TerminalCondition {
+	return TerminalCondition{
+		Name:    "player_state",
+		Outcome: outcome,
+		Message: message,
+		Check: func(s *State) bool {
+			return check(s.Player)
+		},
+	}
+}
+
+func (s *State) RegisterTerminalCondition(condition TerminalCondition) {
+	s.TerminalConditions = append(s.TerminalConditions, condition)
+}
+
+func (s *State) checkTerminalConditions() {
+	if s.GameOver {
+		return
+	}
+	for i := range s.TerminalConditions {
+		condition := &s.TerminalConditions[i]
+		if condition.Check(s) {
+			s.GameOver = true
+			s.Ending = condition
+			s.recordBestScore()
+			return
+		}
+	}
+}
+
+func (s *State) Outcome() Outcome {
+	if s.Ending == nil {
+		return OutcomeNone
+	}
+	return s.Ending.Outcome
+}
+
+func (s *State) CollectedItems() []string {
+	names := make([]string, 0, len(s.Player.WornItems)+len(s.Player.Inventory))
+	for _, item := range s.Player.WornItems {
+		names = append(names, item.Name)
+	}
+	for _, item := range s.Player.Inventory {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func (s *State) gameOverSummary() string {
+	items := MsgNoItemsPicked
+	if names := s.CollectedItems(); len(names) > 0 {
+		items = strings.Join(names, ", ")
+	}
+	return fmt.Sprintf(MsgGameOver, s.Ending.Message, s.Turn, s.Score(), items)
+}
+
+func (s *State) recordBestScore() {
+	if s.Profile == nil || s.Score() <= s.Profile.BestScore {
+		return
+	}
+	s.Profile.BestScore = s.Score()
+	err := s.saveProfile()
+	if err != nil {
+		return
+	}
+}
+
+func (s *State) Restart() {
+	profile, profilePath, eventLog, recorder, aliases, result := s.Profile, s.ProfilePath, s.EventLog, s.Recorder, s.Aliases, s.result
+	clock := s.Clock
+	*s = *NewState()
+	if clock != nil {
+		// Подставленные часы сохраняются, но игра начинается с их начального времени.
+		clock.Reset()
+		s.Clock = clock
+	}
+	s.result = result
+	s.Aliases = aliases
+	buildWorld(s)
+	s.AttachProfile(profile, profilePath)
+	if eventLog != nil {
+		s.AttachEventLog(eventLog)
+	}
+	if recorder != nil {
+		s.AttachRecorder(recorder)
+	}
+}
@@ -5,40 +5,15 @@ import (
 )
 
 func (s *State) RegisterEventHandlers() {
-	s.EventEmitter.On("use", s.handleUseEvent)
+	s.EventEmitter.On(entity.EventRoomEntered, s.handleEnterRoomEvent)
+	s.EventEmitter.On(entity.EventRoomEntered, s.handleLeaveHomeEvent)
 
-	s.EventEmitter.On("enter_room", s.handleEnterRoomEvent)
-}
-
-func (s *State) handleUseEvent(event *entity.Event) error {
-	item, ok := event.Source.(*entity.Item)
-	if !ok {
-		return nil
-	}
-
-	target, ok := event.Target.(*entity.Item)
-	if !ok {
-		return nil
+	for _, eventType := range []entity.EventType{entity.EventRoomEntered, entity.EventDoorOpened, entity.EventItemPicked, entity.EventItemWorn} {
+		s.EventEmitter.On(eventType, s.handleQuestEvent)
 	}
 
-	canOpen, _ := item.GetTrait("can_open").(bool)
-	isOpenable, _ := target.GetTrait("openable").(bool)
-
-	if canOpen && isOpenable {
-		s.DoorOpened = true
-		target.SetTrait("is_open", true)
-
-		doorOpenedEvent := &entity.Event{
-			Type:   "door_opened",
-			Source: item,
-			Target: target,
-			Data:   make(map[string]interface{}),
-		}
-
-		return s.EventEmitter.Emit(doorOpenedEvent)
-	}
-
-	return nil
+	s.EventEmitter.OnAny(s.handleAchievementEvent)
+	s.EventEmitter.OnAny(s.handleDeltaEvent)
 }
 
 func (s *State) handleEnterRoomEvent(event *entity.Event) error {
@@ -49,9 +24,6 @@ func (s *State) handleEnterRoomEvent(event *entity.Event) error {
 
 	switch room.Name {
 	case "кухня":
-		if s.LastCommand == "идти" {
-			room.Description = "ничего интересного"
-		}
 		if len(room.GetItemsByPlace("столе")) > 0 {
 			room.HasHint = true
 		}
@@ -63,3 +35,38 @@ func (s *State) handleEnterRoomEvent(event *entity.Event) error {
 
 	return nil
 }
+
+// attachEmitters направляет события комнат, предметов и игрока в общую шину мира.
+func (s *State) attachEmitters() {
+	s.Player.Emitter.SetParent(s.EventEmitter)
+	for _, item := range s.Player.Inventory {
+		item.Emitter.SetParent(s.EventEmitter)
+	}
+	for _, item := range s.Player.WornItems {
+		item.Emitter.SetParent(s.EventEmitter)
+	}
+
+	for _, room := range s.Rooms {
+		room.Emitter.SetParent(s.EventEmitter)
+		for _, furniture := range room.Furniture {
+			furniture.Emitter.SetParent(s.EventEmitter)
+		}
+		for _, item := range room.GetItems() {
+			item.Emitter.SetParent(s.EventEmitter)
+		}
+	}
+}
+
+func (s *State) AttachEventLog(log *entity.EventLog) {
+	s.EventLog = log
+	log.Attach(s.EventEmitter, entity.AsyncOptions{Policy: entity.BackpressureBlock})
+}
+
+// Close доставляет асинхронные события и закрывает журнал; вызывать при завершении игры.
+func (s *State) Close() error {
+	s.EventEmitter.Close()
+	if s.EventLog != nil {
+		return s.EventLog.Close()
+	}
+	return nil
+}
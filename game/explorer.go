@@ -0,0 +1,321 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+type Violation struct {
+	Commands []string
+	Problem  string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s после: %s", v.Problem, strings.Join(v.Commands, "; "))
+}
+
+type ExploreResult struct {
+	States     int
+	Violations []Violation
+	Solution   []string // Кратчайший путь к победе, nil - победа недостижима
+}
+
+// Explorer перебирает команды, допустимые в текущем состоянии мира, и проверяет инварианты.
+type Explorer struct {
+	NewWorld func() *State
+	MaxDepth int
+}
+
+func NewExplorer(maxDepth int) *Explorer {
+	return &Explorer{
+		NewWorld: InitGame,
+		MaxDepth: maxDepth,
+	}
+}
+
+// Explore обходит состояния в ширину, поэтому найденные последовательности минимальны.
+func (e *Explorer) Explore() ExploreResult {
+	result := ExploreResult{}
+	seen := map[string]bool{}
+	queue := [][]string{{}}
+
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+
+		state, problem := e.run(path)
+		if problem != "" {
+			result.Violations = append(result.Violations, Violation{Commands: path, Problem: problem})
+			continue
+		}
+
+		key := Fingerprint(state)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.States++
+
+		if state.Outcome() == OutcomeWon && result.Solution == nil {
+			result.Solution = path
+		}
+		if state.GameOver || len(path) >= e.MaxDepth {
+			continue
+		}
+
+		for _, command := range ValidCommands(state, false) {
+			next := make([]string, len(path), len(path)+1)
+			copy(next, path)
+			queue = append(queue, append(next, command))
+		}
+	}
+	return result
+}
+
+// Random проигрывает случайные последовательности и сокращает найденные до минимальных.
+func (e *Explorer) Random(seed int64, runs, steps int) []Violation {
+	rng := rand.New(rand.NewSource(seed))
+	var violations []Violation
+
+	for run := 0; run < runs; run++ {
+		state := e.NewWorld()
+		var path []string
+
+		for step := 0; step < steps && !state.GameOver; step++ {
+			commands := ValidCommands(state, true)
+			command := commands[rng.Intn(len(commands))]
+			path = append(path, command)
+
+			if problem := e.step(state, command); problem != "" {
+				violations = append(violations, Violation{Commands: e.Shrink(path), Problem: problem})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// Shrink убирает из последовательности команды, без которых нарушение всё ещё воспроизводится.
+func (e *Explorer) Shrink(commands []string) []string {
+	if _, problem := e.run(commands); problem == "" {
+		return commands
+	}
+
+	shrunk := append([]string(nil), commands...)
+	for i := len(shrunk) - 1; i >= 0; i-- {
+		candidate := append(append([]string(nil), shrunk[:i]...), shrunk[i+1:]...)
+		if _, problem := e.run(candidate); problem != "" {
+			shrunk = candidate
+		}
+	}
+	return shrunk
+}
+
+func (e *Explorer) run(commands []string) (*State, string) {
+	state := e.NewWorld()
+	for _, command := range commands {
+		if problem := e.step(state, command); problem != "" {
+			return state, problem
+		}
+	}
+	return state, ""
+}
+
+func (e *Explorer) step(state *State, command string) (problem string) {
+	defer func() {
+		if r := recover(); r != nil {
+			problem = fmt.Sprintf("паника: %v", r)
+		}
+	}()
+
+	state.HandleCommand(command)
+	if err := CheckInvariants(state); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func ValidCommands(s *State, withLook bool) []string {
+	var commands []string
+	if withLook {
+		commands = append(commands, "осмотреться")
+	}
+
+	room := s.Player.CurrentRoom
+	for direction := range room.Connections {
+		commands = append(commands, "идти "+direction)
+	}
+
+	for _, place := range room.Places {
+		if furniture := room.Furniture[place]; furniture != nil && furniture.Openable {
+			if furniture.Opened {
+				commands = append(commands, "закрыть "+furniture.Name())
+			} else {
+				commands = append(commands, "открыть "+furniture.Name())
+			}
+		}
+	}
+
+	if len(hiddenItems(room, room.Places)) > 0 {
+		commands = append(commands, "искать")
+	}
+
+	for _, item := range room.VisibleItems() {
+		commands = append(commands, "взять "+item.Name)
+		if item.IsWearable() {
+			commands = append(commands, "надеть "+item.Name)
+		}
+	}
+
+	for _, item := range append(room.VisibleItems(), s.Player.Inventory...) {
+		if consumable := item.Consumable(); consumable != nil {
+			if consumable.Kind == "drink" {
+				commands = append(commands, "пить "+item.Name)
+			} else {
+				commands = append(commands, "есть "+item.Name)
+			}
+		}
+	}
+
+	for _, item := range s.Player.Inventory {
+		if light := item.LightSource(); light != nil {
+			if light.Lit {
+				commands = append(commands, "выключить "+item.Name)
+			} else {
+				commands = append(commands, "включить "+item.Name)
+			}
+		}
+		if item.IsWearable() {
+			commands = append(commands, "надеть "+item.Name)
+		}
+		for _, target := range room.VisibleItems() {
+			commands = append(commands, "применить "+item.Name+" "+target.Name)
+		}
+	}
+
+	sort.Strings(commands)
+	return commands
+}
+
+// CheckInvariants сверяет положение предметов с составом мира на момент его создания.
+func CheckInvariants(s *State) error {
+	if s.Player.CurrentRoom == nil {
+		return fmt.Errorf("игрок нигде")
+	}
+	if s.Rooms[s.Player.CurrentRoom.Name] != s.Player.CurrentRoom {
+		return fmt.Errorf("игрок в неизвестной комнате %s", s.Player.CurrentRoom.Name)
+	}
+
+	counts := map[*entity.Item]int{}
+	for name, room := range s.Rooms {
+		for direction, next := range room.Connections {
+			if next == nil {
+				return fmt.Errorf("пустой переход %s -> %s", name, direction)
+			}
+		}
+		for _, item := range room.GetItems() {
+			counts[item]++
+		}
+	}
+	for _, item := range s.Player.Inventory {
+		counts[item]++
+	}
+	for _, item := range s.Player.WornItems {
+		counts[item]++
+	}
+
+	for _, item := range s.Items {
+		expected := 1
+		if consumed, _ := item.GetTrait("consumed").(bool); consumed {
+			expected = 0
+		}
+		if counts[item] != expected {
+			return fmt.Errorf("предмет %s встречается %d раз", item.Name, counts[item])
+		}
+		delete(counts, item)
+	}
+	for item := range counts {
+		return fmt.Errorf("появился неизвестный предмет %s", item.Name)
+	}
+	return nil
+}
+
+// Fingerprint описывает состояние мира без учёта атрибутов игрока. Время входит в отпечаток:
+// от него зависят расписание и концовки (универ закрывается в 09:00).
+func Fingerprint(s *State) string {
+	var parts []string
+	parts = append(parts, "time="+s.Now().Format("15:04"))
+	parts = append(parts, "room="+s.Player.CurrentRoom.Name)
+
+	visited := make([]string, 0, len(s.Player.Visits))
+	for name := range s.Player.Visits {
+		visited = append(visited, name)
+	}
+	sort.Strings(visited)
+	parts = append(parts, "visited="+strings.Join(visited, ","))
+	parts = append(parts, "inventory="+strings.Join(itemNames(s.Player.Inventory), ","))
+	parts = append(parts, "worn="+strings.Join(itemNames(s.Player.WornItems), ","))
+
+	names := make([]string, 0, len(s.Rooms))
+	for name := range s.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		room := s.Rooms[name]
+		places := make([]string, 0, len(room.Items))
+		for place, items := range room.Items {
+			if len(items) > 0 {
+				places = append(places, place+":"+strings.Join(itemNames(items), ","))
+			}
+			if furniture := room.Furniture[place]; furniture != nil && furniture.Opened {
+				places = append(places, place+":открыт")
+			}
+		}
+		sort.Strings(places)
+		parts = append(parts, name+"="+strings.Join(places, ";"))
+	}
+
+	for _, item := range s.Items {
+		if hiding := s.Hidings[item]; hiding != nil && item.IsHidden() {
+			parts = append(parts, fmt.Sprintf("%s=hidden:%d", item.Name, hiding.progress))
+		}
+	}
+
+	for _, item := range s.Items {
+		if light := item.LightSource(); light != nil && light.Lit {
+			parts = append(parts, item.Name+"=lit")
+		}
+	}
+
+	parts = append(parts, fmt.Sprintf("door=%t over=%t", s.DoorOpened, s.GameOver))
+	if s.Quest != nil {
+		for _, objective := range s.Quest.Objectives {
+			parts = append(parts, fmt.Sprintf("%s=%t", objective.Title, objective.Done))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func itemNames(items []*entity.Item) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func WorldItems(s *State) []*entity.Item {
+	var items []*entity.Item
+	for _, room := range s.Rooms {
+		items = append(items, room.GetItems()...)
+	}
+	items = append(items, s.Player.Inventory...)
+	items = append(items, s.Player.WornItems...)
+	return items
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgNothingSpecial  = "ничего особенного"
+	MsgContentsHidden  = "не видно, что %s %s"
+	MsgFurnitureEmpty  = "%s %s пусто"
+	MsgFurnitureOpened = "ты открыл %s"
+	MsgFurnitureClosed = "ты закрыл %s"
+	MsgCannotOpen      = "нельзя открыть %s"
+	MsgCannotClose     = "нельзя закрыть %s"
+	MsgAlreadyOpened   = "уже открыто"
+	MsgAlreadyClosed   = "уже закрыто"
+	MsgOpenFirst       = "сначала надо открыть %s"
+	MsgNoSpace         = "%s %s нет места"
+	MsgItemPut         = "предмет положен %s %s: %s"
+	MsgNoPlace         = "не указано куда"
+)
+
+func (s *State) handleExamine(name string) string {
+	room := s.Player.CurrentRoom
+
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+
+	if furniture := room.FindFurniture(name); furniture != nil {
+		parts := []string{}
+		if furniture.Description != "" {
+			parts = append(parts, furniture.Description)
+		}
+		parts = append(parts, s.furnitureContents(room, furniture))
+		return strings.Join(parts, ", ")
+	}
+
+	item := s.findItemInRoom(room, name)
+	if item == nil {
+		item = s.findItemInInventory(name)
+	}
+	if item == nil {
+		return s.fail(CodeNotFound, MsgItemNotFound)
+	}
+	if item.Description == "" {
+		return MsgNothingSpecial
+	}
+	return item.Description
+}
+
+func (s *State) handleOpen(name string) string {
+	room := s.Player.CurrentRoom
+
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+
+	furniture := room.FindFurniture(name)
+	if furniture == nil {
+		return s.notFurniture(room, name, MsgCannotOpen)
+	}
+	if !furniture.Openable {
+		return s.fail(CodeNotApplicable, fmt.Sprintf(MsgCannotOpen, furniture.Form(entity.Accusative)))
+	}
+	if furniture.Opened {
+		return s.fail(CodeAlreadyDone, MsgAlreadyOpened)
+	}
+
+	furniture.Open(s.Player)
+
+	return fmt.Sprintf(MsgFurnitureOpened, furniture.Form(entity.Accusative)) + ". " + s.furnitureContents(room, furniture)
+}
+
+func (s *State) handleClose(name string) string {
+	room := s.Player.CurrentRoom
+
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+
+	furniture := room.FindFurniture(name)
+	if furniture == nil {
+		return s.notFurniture(room, name, MsgCannotClose)
+	}
+	if !furniture.Openable {
+		return s.fail(CodeNotApplicable, fmt.Sprintf(MsgCannotClose, furniture.Form(entity.Accusative)))
+	}
+	if !furniture.Opened {
+		return s.fail(CodeAlreadyDone, MsgAlreadyClosed)
+	}
+
+	furniture.Close(s.Player)
+
+	return fmt.Sprintf(MsgFurnitureClosed, furniture.Form(entity.Accusative))
+}
+
+func (s *State) handlePut(itemName, furnitureName string) string {
+	room := s.Player.CurrentRoom
+
+	item := s.findItemInInventory(itemName)
+	if item == nil {
+		return s.fail(CodeNotInInventory, fmt.Sprintf(MsgNoItemInInventory, itemName))
+	}
+
+	furniture := room.FindFurniture(furnitureName)
+	if furniture == nil {
+		return s.fail(CodeNotFound, MsgItemNotFound)
+	}
+	if !furniture.ContentsVisible() {
+		return s.fail(CodeClosed, fmt.Sprintf(MsgOpenFirst, furniture.Form(entity.Accusative)))
+	}
+
+	place := furniture.Place()
+	if !room.HasSpace(place) {
+		return s.fail(CodeNoSpace, fmt.Sprintf(MsgNoSpace, furniture.Preposition, place))
+	}
+
+	for i, it := range s.Player.Inventory {
+		if it == item {
+			s.Player.Inventory = append(s.Player.Inventory[:i], s.Player.Inventory[i+1:]...)
+			break
+		}
+	}
+	room.AddItem(item, place)
+	s.itemMoved(item, LocationInventory, place)
+
+	return fmt.Sprintf(MsgItemPut, furniture.Preposition, furniture.Form(entity.Accusative), item.Name)
+}
+
+// notFurniture отвечает на попытку открыть или закрыть то, что мебелью не является.
+func (s *State) notFurniture(room *entity.Room, name, message string) string {
+	if s.findItemInRoom(room, name) != nil || s.findItemInInventory(name) != nil {
+		return s.fail(CodeNotApplicable, fmt.Sprintf(message, name))
+	}
+	return s.fail(CodeNotFound, MsgItemNotFound)
+}
+
+func (s *State) furnitureContents(room *entity.Room, furniture *entity.Furniture) string {
+	place := furniture.Place()
+	if !furniture.ContentsVisible() {
+		return fmt.Sprintf(MsgContentsHidden, furniture.Preposition, place)
+	}
+
+	items := room.VisibleItemsAt(place)
+	if len(items) == 0 {
+		return fmt.Sprintf(MsgFurnitureEmpty, furniture.Preposition, place)
+	}
+	return fmt.Sprintf("%s %s: %s", furniture.Preposition, place, strings.Join(s.getItemNames(items), ", "))
+}
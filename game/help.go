@@ -0,0 +1,166 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	CmdHelp = "помощь"
+
+	MsgHelp           = "команды: %s. подробнее - помощь <команда>"
+	MsgHelpCommand    = "%s - %s"
+	MsgHelpAliases    = ". иначе: %s"
+	MsgUnknownHelp    = "нет такой команды - %s"
+	MsgSuggest        = "%s, может быть: %s?"
+	maxSuggestions    = 3
+	suggestionDivisor = 3 // Допустимое расстояние - треть длины слова
+)
+
+type ArgKind int
+
+const (
+	ArgText            ArgKind = iota // Произвольное слово
+	ArgExit                           // Соседняя комната или сторона света
+	ArgRoomItem                       // Видимый предмет в комнате
+	ArgInventoryItem                  // Предмет в рюкзаке
+	ArgLight                          // Фонарик или свеча в рюкзаке или на игроке
+	ArgItem                           // Предмет в комнате или в рюкзаке
+	ArgWearable                       // Предмет, который можно надеть
+	ArgFurniture                      // Мебель в комнате
+	ArgOpenable                       // Мебель, которую можно открыть и закрыть
+	ArgFurnitureOrItem                // Мебель или предмет
+	ArgCommand                        // Имя команды
+)
+
+type ArgSpec struct {
+	Name     string
+	Kind     ArgKind
+	Optional bool
+}
+
+// CommandInfo описывает команду для помощи, подсказок и автодополнения.
+type CommandInfo struct {
+	Name        string
+	Usage       string // Пусто - собирается из Args
+	Description string
+	Args        []ArgSpec
+	Aliases     []string
+	Hidden      bool // Не показывать в общем списке
+}
+
+func (c *CommandInfo) UsageLine() string {
+	if c.Usage != "" {
+		return c.Usage
+	}
+	parts := []string{c.Name}
+	for _, arg := range c.Args {
+		if arg.Optional {
+			parts = append(parts, "["+arg.Name+"]")
+		} else {
+			parts = append(parts, "<"+arg.Name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// CommandInfo ищет описание команды по имени или другому её названию.
+func (s *State) CommandInfo(name string) *CommandInfo {
+	return s.CommandInfos[name]
+}
+
+// VisibleCommands возвращает имена команд для списка помощи, по алфавиту.
+func (s *State) VisibleCommands() []string {
+	var names []string
+	for name, info := range s.CommandInfos {
+		if info.Name == name && !info.Hidden {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *State) handleHelp(name string) string {
+	if name == "" {
+		return fmt.Sprintf(MsgHelp, strings.Join(s.VisibleCommands(), ", "))
+	}
+
+	info := s.CommandInfo(name)
+	if info == nil {
+		return s.fail(CodeNotFound, fmt.Sprintf(MsgUnknownHelp, name))
+	}
+
+	answer := fmt.Sprintf(MsgHelpCommand, info.UsageLine(), info.Description)
+	if len(info.Aliases) > 0 {
+		answer += fmt.Sprintf(MsgHelpAliases, strings.Join(info.Aliases, ", "))
+	}
+	return answer
+}
+
+// unknownCommand подсказывает ближайшие по расстоянию Левенштейна команды и алиасы.
+func (s *State) unknownCommand(word string) string {
+	if suggestions := s.Suggest(word); len(suggestions) > 0 {
+		return fmt.Sprintf(MsgSuggest, MsgUnknownCommand, strings.Join(suggestions, ", "))
+	}
+	return MsgUnknownCommand
+}
+
+func (s *State) Suggest(word string) []string {
+	limit := len([]rune(word)) / suggestionDivisor
+	if limit == 0 {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(s.CommandInfos)+len(s.Aliases))
+	for name, info := range s.CommandInfos {
+		if !info.Hidden {
+			candidates = append(candidates, name)
+		}
+	}
+	for name := range s.Aliases {
+		candidates = append(candidates, name)
+	}
+
+	best := limit + 1
+	var suggestions []string
+	for _, candidate := range candidates {
+		distance := editDistance(word, candidate)
+		switch {
+		case distance < best:
+			best = distance
+			suggestions = []string{candidate}
+		case distance == best:
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	sort.Strings(suggestions)
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
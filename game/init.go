@@ -4,27 +4,94 @@ import (
 	"github.com/AgDecode/mini-game/entity"
 )
 
+const kitchenLookTemplate = `ты находишься на кухне{{range .Places}}, {{.Preposition}} {{.Name}}: {{join .Items ", "}}{{end}}` +
+	`{{range .Hints}}, {{.}}{{end}}{{if .Exits}}. можно пройти - {{join .Exits ", "}}{{end}}`
+
 func InitGame() *State {
-	state := NewState()
+	state := &State{}
+	state.Restart()
+	return state
+}
+
+func buildWorld(state *State) {
 	registerCommands(state)
 	registerInteractionRules(state)
 	state.RegisterEventHandlers()
+	registerWorldRules(state)
 
-	kitchen := entity.NewRoom("кухня", "ты находишься на кухне", "кухня, ничего интересного. можно пройти - коридор")
+	kitchen := entity.NewRoom("кухня", "ты находишься на кухне", "")
 	corridor := entity.NewRoom("коридор", "ничего интересного", "ничего интересного. можно пройти - кухня, комната, улица")
 	room := entity.NewRoom("комната", "ты в своей комнате", "ты в своей комнате. можно пройти - коридор")
 	street := entity.NewRoom("улица", "на улице весна", "на улице весна. можно пройти - домой")
-	home := entity.NewRoom("домой", "ты дома", "ты дома. можно пройти - улица")
+	home := entity.NewRoom("домой", "ты дома", "ты дома. можно пройти - улица, подвал")
+	cellar := entity.NewRoom("подвал", "сырой подвал", "")
+	cellar.SetTrait("dark", true)
+	cellar.BriefMessage = "подвал"
 	kitchen.HasHint = true
+	kitchen.LookTemplate = kitchenLookTemplate
+	kitchen.RevisitMessage = "кухня, ничего интересного. можно пройти - коридор"
+	kitchen.BriefMessage = "кухня"
+	corridor.BriefMessage = "коридор"
+	room.BriefMessage = "твоя комната"
+	street.BriefMessage = "улица"
+	home.BriefMessage = "дом"
+	street.TimeMessages["вечер"] = "на улице вечереет. можно пройти - домой"
+	street.TimeMessages["ночь"] = "на улице ночь. можно пройти - домой"
 
 	tea := entity.NewItem("чай", "")
 	keys := entity.NewItem("ключи", "")
 	notes := entity.NewItem("конспекты", "")
 	backpack := entity.NewItem("рюкзак", "")
 	door := entity.NewItem("дверь", "закрытая дверь на улицу")
+	jacket := entity.NewItem("куртка", "тёплая куртка")
+	money := entity.NewItem("деньги", "мелочь на проезд")
+	flashlight := entity.NewItem("фонарик", "карманный фонарик")
+	candle := entity.NewItem("свеча", "огарок свечи")
+	jam := entity.NewItem("варенье", "банка малинового варенья")
+
+	kitchenTable := entity.NewFurniture("на", "стол", "стола", "столу", "стол", "столом", "столе")
+	table := entity.NewFurniture("на", "стол", "стола", "столу", "стол", "столом", "столе")
+	chair := entity.NewFurniture("на", "стул", "стула", "стулу", "стул", "стулом", "стуле")
+	wall := entity.NewFurniture("на", "стена", "стены", "стене", "стену", "стеной", "стене")
+	wardrobe := entity.NewFurniture("в", "шкаф", "шкафа", "шкафу", "шкаф", "шкафом", "шкафу")
+	wardrobe.Description = "старый платяной шкаф"
+	wardrobe.Openable = true
+	wardrobe.Capacity = 3
+	homeShelf := entity.NewFurniture("на", "полка", "полки", "полке", "полку", "полкой", "полке")
+	cellarShelf := entity.NewFurniture("на", "полка", "полки", "полке", "полку", "полкой", "полке")
+	bed := entity.NewFurniture("под", "кровать", "кровати", "кровати", "кровать", "кроватью", "кроватью")
+
+	kitchen.AddFurniture(kitchenTable)
+	room.AddFurniture(table)
+	room.AddFurniture(chair)
+	room.AddFurniture(wardrobe)
+	room.AddFurniture(bed)
+	corridor.AddFurniture(wall)
+	home.AddFurniture(homeShelf)
+	cellar.AddFurniture(cellarShelf)
 
 	backpack.SetTrait("wearable", true)
+	jacket.SetTrait("wearable", true)
+	flashlight.SetTrait("light", &entity.LightSource{Fuel: 40})
+	candle.SetTrait("light", &entity.LightSource{Fuel: 10})
+	jam.SetTrait("consumable", &entity.Consumable{
+		Kind: "food",
+		Attributes: map[string]int{
+			entity.AttrEnergy: 10,
+			entity.AttrHunger: -30,
+		},
+	})
+	tea.SetTrait("consumable", &entity.Consumable{
+		Kind: "drink",
+		Attributes: map[string]int{
+			entity.AttrEnergy: 20,
+			entity.AttrHunger: -20,
+		},
+		Effect: entity.NewStatusEffect(EffectFed, 60, map[string]int{entity.AttrHunger: -1}),
+	})
 	keys.SetTrait("can_open", true)
+	keys.SetTrait("key_item", true)
+	notes.SetTrait("key_item", true)
 	door.SetTrait("openable", true)
 	door.SetTrait("is_open", false)
 
@@ -32,59 +99,405 @@ func InitGame() *State {
 	room.AddItem(keys, "столе")
 	room.AddItem(notes, "столе")
 	room.AddItem(backpack, "стуле")
+	room.AddItem(jacket, wardrobe.Place())
+	room.AddItem(flashlight, wardrobe.Place())
+	state.Hide(room, money, bed.Place(), Hiding{Turns: 2, Condition: RequireLight})
+	home.AddItem(candle, homeShelf.Place())
+	cellar.AddItem(jam, cellarShelf.Place())
 	corridor.AddItem(door, "стене")
 
-	kitchen.Connections["коридор"] = corridor
-	corridor.Connections["кухня"] = kitchen
-	corridor.Connections["комната"] = room
-	corridor.Connections["улица"] = street
-	room.Connections["коридор"] = corridor
-	street.Connections["домой"] = home
-	home.Connections["улица"] = street
+	kitchen.ConnectBoth("юг", corridor)
+	corridor.ConnectBoth("восток", room)
+	corridor.Connect("юг", street)
+	corridor.Lock(street, MsgDoorClosed)
+	street.ConnectBoth("запад", home)
+	home.ConnectBoth("вниз", cellar)
 
 	state.Rooms["кухня"] = kitchen
 	state.Rooms["коридор"] = corridor
 	state.Rooms["комната"] = room
 	state.Rooms["улица"] = street
 	state.Rooms["домой"] = home
+	state.Rooms["подвал"] = cellar
 
 	state.Player = entity.NewPlayer(kitchen)
+	state.StartRoom = kitchen
+	state.Items = WorldItems(state)
+	state.attachEmitters()
 
-	return state
+	scheduleWorldEvents(state, tea)
+
+	state.SetQuest(newUniversityQuest())
+
+	registerTerminalConditions(state)
+
+	registerScoring(state)
 }
 
 func registerCommands(state *State) {
-	state.RegisterCommand("осмотреться", func(s *State, args []string) string {
+	state.RegisterCommand("осмотреться", CommandInfo{
+		Description: "описать комнату, в которой ты находишься",
+	}, func(s *State, args []string) string {
 		return s.handleLook()
 	})
 
-	state.RegisterCommand("идти", func(s *State, args []string) string {
+	state.RegisterCommand("идти", CommandInfo{
+		Usage:       "идти <комната|сторона света> или идти до <комната>",
+		Description: "перейти в соседнюю комнату или дойти до знакомой",
+		Args:        []ArgSpec{{Name: "куда", Kind: ArgExit}},
+	}, func(s *State, args []string) string {
 		if len(args) == 0 {
-			return MsgNoDirection
+			return s.fail(CodeMissingArg, MsgNoDirection)
+		}
+		if args[0] == "до" {
+			if len(args) == 1 {
+				return s.fail(CodeMissingArg, MsgNoTarget)
+			}
+			return s.handleGoTo(args[1])
 		}
 		return s.handleGo(args[0])
 	})
 
-	state.RegisterCommand("взять", func(s *State, args []string) string {
+	state.RegisterCommand("взять", CommandInfo{
+		Description: "положить предмет из комнаты в рюкзак",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgRoomItem}},
+	}, func(s *State, args []string) string {
 		if len(args) == 0 {
-			return MsgNoItem
+			return s.fail(CodeMissingArg, MsgNoItem)
 		}
 		return s.handleTake(args[0])
 	})
 
-	state.RegisterCommand("надеть", func(s *State, args []string) string {
+	state.RegisterCommand("надеть", CommandInfo{
+		Description: "надеть вещь из комнаты или из рюкзака",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgWearable}},
+	}, func(s *State, args []string) string {
 		if len(args) == 0 {
-			return MsgNoItem
+			return s.fail(CodeMissingArg, MsgNoItem)
 		}
 		return s.handleWear(args[0])
 	})
 
-	state.RegisterCommand("применить", func(s *State, args []string) string {
+	state.RegisterCommand("применить", CommandInfo{
+		Description: "применить предмет из рюкзака к чему-то в комнате",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgInventoryItem}, {Name: "к чему", Kind: ArgRoomItem}},
+	}, func(s *State, args []string) string {
 		if len(args) < 2 {
-			return MsgNoItems
+			return s.fail(CodeMissingArg, MsgNoItems)
 		}
 		return s.handleUse(args[0], args[1])
 	})
+
+	state.RegisterCommand("осмотреть", CommandInfo{
+		Description: "рассмотреть мебель или предмет",
+		Args:        []ArgSpec{{Name: "что", Kind: ArgFurnitureOrItem}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleExamine(args[0])
+	})
+
+	state.RegisterCommand("открыть", CommandInfo{
+		Description: "открыть шкаф или другую мебель",
+		Args:        []ArgSpec{{Name: "что", Kind: ArgOpenable}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleOpen(args[0])
+	})
+
+	state.RegisterCommand("закрыть", CommandInfo{
+		Description: "закрыть шкаф или другую мебель",
+		Args:        []ArgSpec{{Name: "что", Kind: ArgOpenable}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleClose(args[0])
+	})
+
+	state.RegisterCommand("положить", CommandInfo{
+		Usage:       "положить <предмет> [на|в|под] <мебель>",
+		Description: "выложить предмет из рюкзака на мебель",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgInventoryItem}, {Name: "куда", Kind: ArgFurniture}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		if len(args) == 1 {
+			return s.fail(CodeMissingArg, MsgNoPlace)
+		}
+		return s.handlePut(args[0], args[len(args)-1])
+	})
+
+	state.RegisterCommand("обыскать", CommandInfo{
+		Description: "поискать спрятанное в мебели или во всей комнате",
+		Args:        []ArgSpec{{Name: "место", Kind: ArgFurniture, Optional: true}},
+		Aliases:     []string{"искать"},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.handleSearch("")
+		}
+		return s.handleSearch(args[len(args)-1])
+	})
+
+	state.RegisterCommand("включить", CommandInfo{
+		Description: "зажечь фонарик или свечу",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgLight}},
+		Aliases:     []string{"зажечь"},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleLight(args[0], true)
+	})
+
+	state.RegisterCommand("выключить", CommandInfo{
+		Description: "погасить фонарик или свечу",
+		Args:        []ArgSpec{{Name: "предмет", Kind: ArgLight}},
+		Aliases:     []string{"потушить"},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleLight(args[0], false)
+	})
+
+	state.RegisterCommand("время", CommandInfo{
+		Description: "узнать, который час",
+	}, func(s *State, args []string) string {
+		return s.handleTime()
+	})
+
+	state.RegisterCommand("задания", CommandInfo{
+		Description: "показать задания и их выполнение",
+	}, func(s *State, args []string) string {
+		return s.handleQuests()
+	})
+
+	state.RegisterCommand("есть", CommandInfo{
+		Description: "съесть что-нибудь",
+		Args:        []ArgSpec{{Name: "еда", Kind: ArgItem}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleConsume("food", args[0])
+	})
+
+	state.RegisterCommand("пить", CommandInfo{
+		Description: "выпить что-нибудь",
+		Args:        []ArgSpec{{Name: "напиток", Kind: ArgItem}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.fail(CodeMissingArg, MsgNoItem)
+		}
+		return s.handleConsume("drink", args[0])
+	})
+
+	state.RegisterCommand("состояние", CommandInfo{
+		Description: "здоровье, энергия и голод",
+	}, func(s *State, args []string) string {
+		return s.handleStatus()
+	})
+
+	state.RegisterCommand("подробно", CommandInfo{
+		Description: "полные описания комнат",
+	}, func(s *State, args []string) string {
+		return s.handleVerbosity(entity.VerbosityFull)
+	})
+
+	state.RegisterCommand("кратко", CommandInfo{
+		Description: "короткие описания уже знакомых комнат",
+	}, func(s *State, args []string) string {
+		return s.handleVerbosity(entity.VerbosityBrief)
+	})
+
+	state.RegisterCommand("компас", CommandInfo{
+		Description: "показывать или скрывать стороны света у выходов",
+	}, func(s *State, args []string) string {
+		return s.handleCompass()
+	})
+
+	for _, direction := range entity.CompassDirections() {
+		state.RegisterCommand(direction, CommandInfo{
+			Description: "идти " + direction,
+			Aliases:     entity.ShortDirections(direction),
+			Hidden:      true,
+		}, func(s *State, args []string) string {
+			return s.handleGo(direction)
+		})
+	}
+
+	state.RegisterCommand(CmdAliases, CommandInfo{
+		Description: "показать заданные алиасы и макросы",
+	}, func(s *State, args []string) string {
+		return s.handleAliases()
+	})
+
+	state.RegisterCommand(CmdAlias, CommandInfo{
+		Usage:       MsgAliasUsage,
+		Description: "задать сокращение для команды или несколько команд сразу",
+		Args:        []ArgSpec{{Name: "имя", Kind: ArgText}, {Name: "команды", Kind: ArgText}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.handleAliases()
+		}
+		return s.fail(CodeBadAlias, MsgAliasUsage)
+	})
+
+	state.RegisterCommand("карта", CommandInfo{
+		Description: "нарисовать карту знакомых комнат",
+	}, func(s *State, args []string) string {
+		return RenderASCIIMap(s, true)
+	})
+
+	state.RegisterCommand("счёт", CommandInfo{
+		Description: "очки и достижения",
+	}, func(s *State, args []string) string {
+		return s.handleScore()
+	})
+
+	state.RegisterCommand(CmdHelp, CommandInfo{
+		Description: "список команд или описание одной из них",
+		Args:        []ArgSpec{{Name: "команда", Kind: ArgCommand, Optional: true}},
+	}, func(s *State, args []string) string {
+		if len(args) == 0 {
+			return s.handleHelp("")
+		}
+		return s.handleHelp(args[0])
+	})
+
+	state.RegisterCommand(CmdRestart, CommandInfo{
+		Description: "начать игру сначала",
+	}, func(s *State, args []string) string {
+		s.Restart()
+		return MsgRestarted
+	})
+}
+
+func newUniversityQuest() *Quest {
+	return NewQuest("в универ",
+		&Objective{
+			Title: "надеть рюкзак",
+			Hint:  "надо собрать рюкзак и идти в универ",
+			Condition: func(s *State) bool {
+				return s.Player.HasBackpack()
+			},
+		},
+		&Objective{
+			Title: "взять конспекты",
+			Hint:  "надо собрать рюкзак и идти в универ",
+			Condition: func(s *State) bool {
+				return s.Player.HasItem("конспекты")
+			},
+		},
+		&Objective{
+			Title: "дойти до универа",
+			Hint:  "надо идти в универ",
+			Condition: func(s *State) bool {
+				return s.Player.CurrentRoom.Name == "улица" && !s.UniversityClosed
+			},
+		},
+	)
+}
+
+func registerScoring(state *State) {
+	state.RegisterScoreRule(ScoreRule{
+		Event:  entity.EventRoomEntered,
+		Points: 5,
+		Key:    eventRoomName,
+	})
+
+	state.RegisterScoreRule(ScoreRule{
+		Event:  entity.EventItemPicked,
+		Points: 10,
+		Match: func(s *State, event *entity.Event) bool {
+			payload, ok := entity.PayloadOf[entity.ItemPayload](event)
+			return ok && payload.Item.HasTrait("key_item")
+		},
+		Key: eventItemName,
+	})
+
+	state.RegisterScoreRule(ScoreRule{
+		Event:  entity.EventDoorOpened,
+		Points: 10,
+		Key:    eventItemName,
+	})
+
+	state.RegisterScoreRule(ScoreRule{
+		Event:  entity.EventObjectiveCompleted,
+		Points: 20,
+		Key:    eventObjectiveTitle,
+	})
+
+	state.RegisterAchievement(Achievement{
+		ID:    "locksmith",
+		Title: "взломщик",
+		Condition: func(s *State, event *entity.Event) bool {
+			return event.Type == entity.EventDoorOpened
+		},
+	})
+
+	state.RegisterAchievement(Achievement{
+		ID:    "nerd",
+		Title: "отличник",
+		Condition: func(s *State, event *entity.Event) bool {
+			return event.Type == entity.EventItemPicked && eventItemName(event) == "конспекты"
+		},
+	})
+
+	state.RegisterAchievement(Achievement{
+		ID:    "early_bird",
+		Title: "ранняя пташка",
+		Condition: func(s *State, event *entity.Event) bool {
+			return event.Type == entity.EventObjectiveCompleted &&
+				eventObjectiveTitle(event) == "дойти до универа" &&
+				s.Now().Before(s.At(8, 15))
+		},
+	})
+}
+
+func registerTerminalConditions(state *State) {
+	state.RegisterTerminalCondition(TerminalCondition{
+		Name:    "quest_completed",
+		Outcome: OutcomeWon,
+		Message: "ты дошёл до универа",
+		Check: func(s *State) bool {
+			return s.Won
+		},
+	})
+
+	state.RegisterTerminalCondition(TerminalCondition{
+		Name:    "late",
+		Outcome: OutcomeLost,
+		Message: "универ уже закрыт, ты опоздал",
+		Check: func(s *State) bool {
+			return s.Player.CurrentRoom.Name == "улица" && s.UniversityClosed
+		},
+	})
+
+	state.RegisterTerminalCondition(PlayerCondition(OutcomeLost, "ты упал от истощения", func(p *entity.Player) bool {
+		return p.GetAttribute(entity.AttrHealth) == 0
+	}))
+
+	state.RegisterTerminalCondition(TurnLimit(200, "ты так и не вышел из дома"))
+}
+
+func scheduleWorldEvents(state *State, tea *entity.Item) {
+	state.ScheduleAt(8, 15, "чай остыл", func(s *State) {
+		tea.SetTrait("cold", true)
+		tea.Description = "холодный чай"
+		if consumable := tea.Consumable(); consumable != nil {
+			consumable.Attributes[entity.AttrEnergy] = 5
+		}
+	})
+
+	state.ScheduleAt(9, 0, "универ закрылся", func(s *State) {
+		s.UniversityClosed = true
+	})
 }
 
 func registerInteractionRules(state *State) {
@@ -98,16 +511,13 @@ func registerInteractionRules(state *State) {
 		},
 		StateModifier: func(s *State, source, target *entity.Item) {
 			s.DoorOpened = true
+			s.Rooms["коридор"].Unlock(s.Rooms["улица"])
 			target.SetTrait("is_open", true)
+			target.Description = "открытая дверь на улицу"
 		},
 		EventEmitter: func(s *State, source, target *entity.Item) {
-			doorOpenedEvent := &entity.Event{
-				Type:   "door_opened",
-				Source: source,
-				Target: target,
-				Data:   make(map[string]interface{}),
-			}
-			err := s.EventEmitter.Emit(doorOpenedEvent)
+			doorOpenedEvent := entity.NewEvent(entity.EventDoorOpened, source, target, entity.UsePayload{Item: source, Target: target})
+			err := target.Emitter.Emit(doorOpenedEvent)
 			if err != nil {
 				return
 			}
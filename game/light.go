@@ -0,0 +1,98 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgTooDark     = "слишком темно, ничего не видно"
+	MsgTooDarkToGo = "в темноте не видно, куда идти"
+	MsgNeedLight   = "без света тут ничего не найти"
+	MsgNotLight    = "нельзя зажечь %s"
+	MsgLightOn     = "%s горит"
+	MsgLightOff    = "%s не горит"
+	MsgAlreadyLit  = "уже горит"
+	MsgAlreadyOut  = "и так не горит"
+	MsgNoFuel      = "%s больше не светит"
+	MsgLightOut    = "%s догорает и гаснет"
+)
+
+// CanSee - в комнате светло или у игрока с собой горящий источник света.
+func (s *State) CanSee(room *entity.Room) bool {
+	return !room.IsDark() || s.Player.HasLight()
+}
+
+// RequireLight - условие для тайников, которые не найти без света.
+func RequireLight(s *State) string {
+	if !s.Player.HasLight() {
+		return MsgNeedLight
+	}
+	return ""
+}
+
+func (s *State) handleLight(itemName string, lit bool) string {
+	item := s.findItemInInventory(itemName)
+	if item == nil {
+		for _, worn := range s.Player.WornItems {
+			if worn.Name == itemName {
+				item = worn
+			}
+		}
+	}
+	if item == nil {
+		return s.fail(CodeNotInInventory, fmt.Sprintf(MsgNoItemInInventory, itemName))
+	}
+
+	light := item.LightSource()
+	if light == nil {
+		return s.fail(CodeNotApplicable, fmt.Sprintf(MsgNotLight, itemName))
+	}
+
+	if !lit {
+		if !light.Lit {
+			return s.fail(CodeAlreadyDone, MsgAlreadyOut)
+		}
+		light.Lit = false
+		s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: itemName, Key: "lit", Value: false})
+		return fmt.Sprintf(MsgLightOff, itemName)
+	}
+
+	if light.Lit {
+		return s.fail(CodeAlreadyDone, MsgAlreadyLit)
+	}
+	if !light.HasFuel() {
+		return s.fail(CodeNoFuel, fmt.Sprintf(MsgNoFuel, itemName))
+	}
+	light.Lit = true
+	s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: itemName, Key: "lit", Value: true})
+
+	answer := fmt.Sprintf(MsgLightOn, itemName)
+	if s.Player.CurrentRoom.IsDark() {
+		answer += ". " + s.handleLook()
+	}
+	return answer
+}
+
+// tickLights сжигает топливо у всех горящих источников света в мире.
+// О погасших в руках игрока он ему сообщает.
+func (s *State) tickLights() {
+	for _, item := range s.Items {
+		light := item.LightSource()
+		if light == nil || !light.Burn() {
+			continue
+		}
+		if !slices.Contains(s.Player.Inventory, item) && !slices.Contains(s.Player.WornItems, item) {
+			item.OnLightOut(nil)
+			continue
+		}
+		item.OnLightOut(s.Player)
+		notice := fmt.Sprintf(MsgLightOut, item.Name)
+		if !s.CanSee(s.Player.CurrentRoom) {
+			notice += ". " + MsgTooDark
+		}
+		s.notices = append(s.notices, notice)
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	EffectHungry    = "голоден"
+	EffectFed       = "сыт"
+	EffectExhausted = "без сил"
+
+	HungerThreshold = 60
+
+	MsgConsumed      = "ты %s: %s"
+	MsgConsumedCold  = "ты %s: %s, он уже остыл"
+	MsgCannotEat     = "это нельзя есть"
+	MsgCannotDrink   = "это нельзя пить"
+	MsgNoEnergy      = "нет сил идти, надо подкрепиться"
+	MsgHungryHint    = "в животе урчит"
+	MsgStatus        = "здоровье: %d, энергия: %d, голод: %d, состояние: %s"
+	MsgStatusHealthy = "в порядке"
+)
+
+var consumeVerbs = map[string]string{
+	"food":  "съел",
+	"drink": "выпил",
+}
+
+func (s *State) handleConsume(kind, itemName string) string {
+	room := s.Player.CurrentRoom
+
+	item, place := s.findConsumable(room, itemName)
+	if item == nil {
+		return s.fail(CodeNotFound, MsgItemNotFound)
+	}
+
+	consumable := item.Consumable()
+	if consumable == nil || consumable.Kind != kind {
+		if kind == "drink" {
+			return s.fail(CodeNotApplicable, MsgCannotDrink)
+		}
+		return s.fail(CodeNotApplicable, MsgCannotEat)
+	}
+
+	if place == LocationInventory {
+		s.Player.Inventory = slices.DeleteFunc(s.Player.Inventory, func(it *entity.Item) bool { return it == item })
+	} else {
+		// Съесть со стола - всё равно что взять: правила, запрещающие брать, запрещают и это
+		if message, ok := item.CanPickup(s.Player, place); !ok {
+			return s.block(message)
+		}
+		s.removeItemFromRoom(room, item, place)
+		s.updateRoomDescriptionIfEmpty(room)
+	}
+	s.itemMoved(item, place, "")
+	item.SetTrait("consumed", true)
+
+	before := s.snapshotPlayer()
+	s.Player.Consume(consumable)
+	s.applyAttributeRules()
+	s.playerChanged(before)
+
+	item.OnConsume(s.Player)
+
+	if cold, _ := item.GetTrait("cold").(bool); cold {
+		return fmt.Sprintf(MsgConsumedCold, consumeVerbs[kind], itemName)
+	}
+	return fmt.Sprintf(MsgConsumed, consumeVerbs[kind], itemName)
+}
+
+// findConsumable ищет еду среди видимого в комнате и в рюкзаке; place для рюкзака - LocationInventory.
+func (s *State) findConsumable(room *entity.Room, itemName string) (*entity.Item, string) {
+	if item, place := s.findItemWithLocation(room, itemName); item != nil {
+		return item, place
+	}
+	if item := s.findItemInInventory(itemName); item != nil {
+		return item, LocationInventory
+	}
+	return nil, ""
+}
+
+// playerSnapshot - атрибуты и эффекты игрока до действия.
+type playerSnapshot struct {
+	attributes map[string]int
+	effects    []string
+}
+
+func (s *State) snapshotPlayer() playerSnapshot {
+	return playerSnapshot{
+		attributes: maps.Clone(s.Player.Attributes),
+		effects:    s.effectNames(),
+	}
+}
+
+// playerChanged записывает в Result, как изменились атрибуты и эффекты игрока со снимка before.
+func (s *State) playerChanged(before playerSnapshot) {
+	for _, attr := range slices.Sorted(maps.Keys(s.Player.Attributes)) {
+		if value := s.Player.Attributes[attr]; value != before.attributes[attr] {
+			s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: SubjectPlayer, Key: attr, Value: value})
+		}
+	}
+
+	after := s.effectNames()
+	for _, name := range before.effects {
+		if !slices.Contains(after, name) {
+			s.addDelta(Delta{Kind: DeltaEffectRemoved, Subject: SubjectPlayer, Key: name})
+		}
+	}
+	for _, name := range after {
+		if !slices.Contains(before.effects, name) {
+			s.addDelta(Delta{Kind: DeltaEffectAdded, Subject: SubjectPlayer, Key: name})
+		}
+	}
+}
+
+func (s *State) effectNames() []string {
+	names := make([]string, len(s.Player.Effects))
+	for i, effect := range s.Player.Effects {
+		names[i] = effect.Name
+	}
+	return names
+}
+
+func (s *State) tickPlayer() {
+	s.Player.ChangeAttribute(entity.AttrHunger, 1)
+	s.Player.TickEffects()
+	if s.Player.GetAttribute(entity.AttrEnergy) == 0 {
+		s.Player.ChangeAttribute(entity.AttrHealth, -1)
+	}
+	s.applyAttributeRules()
+}
+
+func (s *State) applyAttributeRules() {
+	player := s.Player
+
+	if player.GetAttribute(entity.AttrHunger) >= HungerThreshold {
+		if !player.HasEffect(EffectHungry) {
+			player.AddEffect(entity.NewStatusEffect(EffectHungry, 0, map[string]int{entity.AttrEnergy: -1}))
+		}
+	} else {
+		player.RemoveEffect(EffectHungry)
+	}
+
+	if player.GetAttribute(entity.AttrEnergy) == 0 {
+		if !player.HasEffect(EffectExhausted) {
+			player.AddEffect(entity.NewStatusEffect(EffectExhausted, 0, nil))
+		}
+	} else {
+		player.RemoveEffect(EffectExhausted)
+	}
+}
+
+func (s *State) handleLeaveHomeEvent(event *entity.Event) error {
+	room, ok := event.Target.(*entity.Room)
+	if !ok || room.Name != "улица" {
+		return nil
+	}
+
+	// Не позавтракал - идёт в универ голодным
+	if !s.Player.HasEffect(EffectFed) && s.Player.GetAttribute(entity.AttrHunger) < HungerThreshold {
+		s.Player.SetAttribute(entity.AttrHunger, HungerThreshold)
+		s.applyAttributeRules()
+	}
+	return nil
+}
+
+func (s *State) handleStatus() string {
+	names := s.effectNames()
+
+	effects := MsgStatusHealthy
+	if len(names) > 0 {
+		effects = strings.Join(names, ", ")
+	}
+
+	return fmt.Sprintf(MsgStatus,
+		s.Player.GetAttribute(entity.AttrHealth),
+		s.Player.GetAttribute(entity.AttrEnergy),
+		s.Player.GetAttribute(entity.AttrHunger),
+		effects)
+}
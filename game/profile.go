@@ -0,0 +1,75 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+type Profile struct {
+	Name         string            `json:"name"`
+	Achievements []string          `json:"achievements"`
+	BestScore    int               `json:"best_score"`
+	Aliases      map[string]string `json:"aliases,omitempty"`
+}
+
+func NewProfile(name string) *Profile {
+	return &Profile{
+		Name:         name,
+		Achievements: make([]string, 0),
+	}
+}
+
+func LoadProfile(path, name string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewProfile(name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile := NewProfile(name)
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (p *Profile) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (p *Profile) HasAchievement(id string) bool {
+	for _, unlocked := range p.Achievements {
+		if unlocked == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Profile) Unlock(id string) {
+	if !p.HasAchievement(id) {
+		p.Achievements = append(p.Achievements, id)
+	}
+}
+
+func (s *State) AttachProfile(profile *Profile, path string) {
+	s.Profile = profile
+	s.ProfilePath = path
+	if profile != nil && profile.Aliases != nil {
+		s.Aliases = profile.Aliases
+	}
+}
+
+func (s *State) saveProfile() error {
+	if s.Profile == nil || s.ProfilePath == "" {
+		return nil
+	}
+	return s.Profile.Save(s.ProfilePath)
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgQuests        = "задания: %s"
+	MsgQuestDone     = "%s (выполнено)"
+	MsgQuestsNone    = "заданий нет"
+	MsgQuestComplete = "все задания выполнены, ты победил"
+)
+
+type Objective struct {
+	Title     string
+	Hint      string
+	Condition func(*State) bool
+	Done      bool
+}
+
+type ObjectivePayload struct {
+	Objective *Objective
+}
+
+type Quest struct {
+	Title      string
+	Objectives []*Objective
+}
+
+func (o *Objective) String() string {
+	return o.Title
+}
+
+func (q *Quest) String() string {
+	return q.Title
+}
+
+func NewQuest(title string, objectives ...*Objective) *Quest {
+	return &Quest{
+		Title:      title,
+		Objectives: objectives,
+	}
+}
+
+func (q *Quest) Next() *Objective {
+	for _, objective := range q.Objectives {
+		if !objective.Done {
+			return objective
+		}
+	}
+	return nil
+}
+
+func (q *Quest) Completed() bool {
+	return q.Next() == nil
+}
+
+func (s *State) SetQuest(quest *Quest) {
+	s.Quest = quest
+	s.updateQuest()
+}
+
+func (s *State) QuestHint() string {
+	if s.Quest == nil {
+		return ""
+	}
+	if next := s.Quest.Next(); next != nil {
+		return next.Hint
+	}
+	return ""
+}
+
+func (s *State) handleQuestEvent(event *entity.Event) error {
+	s.updateQuest()
+	return nil
+}
+
+func (s *State) updateQuest() {
+	if s.Quest == nil || s.Won {
+		return
+	}
+
+	// Задания выполняются по порядку: следующее проверяется, только когда закрыто предыдущее.
+	for objective := s.Quest.Next(); objective != nil && objective.Condition(s); objective = s.Quest.Next() {
+		objective.Done = true
+		s.emitObjectiveCompleted(objective)
+	}
+
+	if s.Quest.Completed() {
+		s.Won = true
+	}
+}
+
+func (s *State) emitObjectiveCompleted(objective *Objective) {
+	event := entity.NewEvent(entity.EventObjectiveCompleted, s.Quest, objective, ObjectivePayload{Objective: objective})
+	err := s.EventEmitter.Emit(event)
+	if err != nil {
+		return
+	}
+}
+
+func (s *State) handleQuests() string {
+	if s.Quest == nil || len(s.Quest.Objectives) == 0 {
+		return MsgQuestsNone
+	}
+	if s.Won {
+		return MsgQuestComplete
+	}
+
+	titles := make([]string, len(s.Quest.Objectives))
+	for i, objective := range s.Quest.Objectives {
+		if objective.Done {
+			titles[i] = fmt.Sprintf(MsgQuestDone, objective.Title)
+		} else {
+			titles[i] = objective.Title
+		}
+	}
+	return fmt.Sprintf(MsgQuests, strings.Join(titles, ", "))
+}
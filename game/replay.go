@@ -0,0 +1,192 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+// WorldVersion меняется при любом изменении мира или движка, влияющем на ответы.
+const WorldVersion = "1"
+
+type RecordingHeader struct {
+	Version      string            `json:"version"`
+	Start        time.Time         `json:"start"`
+	TurnLength   time.Duration     `json:"turn_length"`
+	Aliases      map[string]string `json:"aliases,omitempty"`      // Алиасы на момент начала записи
+	Achievements []string          `json:"achievements,omitempty"` // Достижения из профиля: повторно они не открываются
+}
+
+type RecordedStep struct {
+	Step    int                `json:"step"`
+	Command string             `json:"command"`
+	Reply   string             `json:"reply"`
+	Events  []entity.EventType `json:"events,omitempty"`
+}
+
+type Recording struct {
+	RecordingHeader
+	Steps []RecordedStep
+}
+
+type Divergence struct {
+	Step     int
+	Command  string
+	Expected RecordedStep
+	Actual   RecordedStep
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("шаг %d (%q): ожидалось %q %v, получено %q %v",
+		d.Step, d.Command, d.Expected.Reply, d.Expected.Events, d.Actual.Reply, d.Actual.Events)
+}
+
+// Recorder дописывает в журнал каждую команду, ответ и события, которые она вызвала.
+type Recorder struct {
+	encoder *json.Encoder
+	header  bool
+	step    int
+	events  []entity.EventType
+	last    RecordedStep
+	err     error
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+func (s *State) AttachRecorder(recorder *Recorder) {
+	s.Recorder = recorder
+	// Первым в очереди, чтобы попали и события, которые следующий обработчик отменит.
+	s.EventEmitter.OnPriority(entity.AnyEvent, math.MaxInt, func(event *entity.Event) error {
+		if event.Probe {
+			return nil
+		}
+		recorder.events = append(recorder.events, event.Type)
+		return nil
+	})
+
+	if recorder.header {
+		return
+	}
+	recorder.header = true
+	start := s.Clock.Now()
+	turnLength := DefaultTurnLength
+	if clock, ok := s.Clock.(*TurnClock); ok {
+		turnLength = clock.step
+	}
+	header := RecordingHeader{
+		Version:    WorldVersion,
+		Start:      start,
+		TurnLength: turnLength,
+	}
+	if len(s.Aliases) > 0 {
+		header.Aliases = maps.Clone(s.Aliases)
+	}
+	if s.Profile != nil && len(s.Profile.Achievements) > 0 {
+		header.Achievements = slices.Clone(s.Profile.Achievements)
+	}
+	recorder.write(header)
+}
+
+func (r *Recorder) begin() {
+	r.events = r.events[:0]
+}
+
+func (r *Recorder) record(command, reply string) {
+	r.step++
+	step := RecordedStep{
+		Step:    r.step,
+		Command: command,
+		Reply:   reply,
+	}
+	if len(r.events) > 0 {
+		step.Events = append([]entity.EventType(nil), r.events...)
+	}
+	r.last = step
+	r.write(step)
+}
+
+func (r *Recorder) write(value interface{}) {
+	if r.err != nil {
+		return
+	}
+	r.err = r.encoder.Encode(value)
+}
+
+func LoadRecording(reader io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("пустой журнал")
+	}
+
+	recording := &Recording{}
+	if err := json.Unmarshal(scanner.Bytes(), &recording.RecordingHeader); err != nil {
+		return nil, fmt.Errorf("заголовок журнала: %w", err)
+	}
+
+	for scanner.Scan() {
+		var step RecordedStep
+		if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+			return nil, fmt.Errorf("шаг %d: %w", len(recording.Steps)+1, err)
+		}
+		recording.Steps = append(recording.Steps, step)
+	}
+	return recording, scanner.Err()
+}
+
+// Replay заново проигрывает первые steps команд журнала и останавливается на первом расхождении.
+func Replay(recording *Recording, steps int) (*State, *Divergence) {
+	if steps > len(recording.Steps) {
+		steps = len(recording.Steps)
+	}
+
+	state := InitGame()
+	state.SetClock(NewTurnClock(recording.Start, recording.TurnLength))
+	if recording.Aliases != nil || recording.Achievements != nil {
+		// Профиль без пути: воспроизведение ничего не сохраняет.
+		profile := NewProfile("")
+		profile.Achievements = append(profile.Achievements, recording.Achievements...)
+		profile.Aliases = maps.Clone(recording.Aliases)
+		state.AttachProfile(profile, "")
+	}
+
+	recorder := NewRecorder(io.Discard)
+	state.AttachRecorder(recorder)
+
+	for _, expected := range recording.Steps[:steps] {
+		state.HandleCommand(expected.Command)
+
+		actual := recorder.last
+		actual.Step = expected.Step
+		if actual.Reply != expected.Reply || !reflect.DeepEqual(actual.Events, expected.Events) {
+			return state, &Divergence{
+				Step:     expected.Step,
+				Command:  expected.Command,
+				Expected: expected,
+				Actual:   actual,
+			}
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"github.com/AgDecode/mini-game/entity"
+)
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusError   Status = "error"   // Команда не понята или не применима
+	StatusBlocked Status = "blocked" // Команда понятна, но её запретило правило мира
+)
+
+// Коды ошибок для клиентов; текст для игрока может меняться, коды - нет.
+const (
+	CodeUnknownCommand = "unknown_command"
+	CodeMissingArg     = "missing_argument"
+	CodeNotFound       = "not_found"
+	CodeNotInInventory = "not_in_inventory"
+	CodeNoBackpack     = "no_backpack"
+	CodeNoPath         = "no_path"
+	CodeNotApplicable  = "not_applicable"
+	CodeAlreadyDone    = "already_done"
+	CodeClosed         = "closed"
+	CodeNoSpace        = "no_space"
+	CodeTooDark        = "too_dark"
+	CodeNoFuel         = "no_fuel"
+	CodeRuleBlocked    = "rule_blocked"
+	CodeGameOver       = "game_over"
+	CodeBadAlias       = "bad_alias"
+	CodeTooManySteps   = "too_many_steps"
+)
+
+type DeltaKind string
+
+const (
+	DeltaMoved         DeltaKind = "moved"
+	DeltaItemAdded     DeltaKind = "item_added"
+	DeltaItemRemoved   DeltaKind = "item_removed"
+	DeltaTraitChanged  DeltaKind = "trait_changed"
+	DeltaEffectAdded   DeltaKind = "effect_added"
+	DeltaEffectRemoved DeltaKind = "effect_removed"
+)
+
+// SubjectPlayer - Delta.Subject для изменений самого игрока.
+const SubjectPlayer = "игрок"
+
+// Места предметов в Delta.Key, кроме мест в комнате ("столе", "шкафу").
+const (
+	LocationInventory = "inventory"
+	LocationWorn      = "worn"
+)
+
+// Delta - одно изменение мира, произошедшее за команду.
+type Delta struct {
+	Kind    DeltaKind
+	Subject string      // Кто или что изменилось
+	Key     string      // Для trait_changed - имя свойства; для item_added - куда, для item_removed - откуда; для effect_* - имя эффекта
+	Value   interface{} // Новое значение
+}
+
+// Result - ответ на команду для клиентов: текст для игрока, статус, код ошибки и изменения мира.
+type Result struct {
+	Status Status
+	Text   string
+	Code   string
+	Deltas []Delta
+}
+
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Execute выполняет строку команд и возвращает структурированный результат.
+func (s *State) Execute(command string) Result {
+	result := &Result{Status: StatusOK}
+	s.result = result
+
+	if s.Recorder == nil {
+		result.Text = s.handleCommand(command)
+	} else {
+		recorder := s.Recorder
+		recorder.begin()
+		result.Text = s.handleCommand(command)
+		recorder.record(command, result.Text)
+	}
+
+	s.result = nil
+	return *result
+}
+
+// fail отмечает текущую команду как ошибочную и возвращает текст для игрока.
+func (s *State) fail(code, text string) string {
+	s.setStatus(StatusError, code)
+	return text
+}
+
+// block отмечает, что действие запретило правило мира.
+func (s *State) block(text string) string {
+	s.setStatus(StatusBlocked, CodeRuleBlocked)
+	return text
+}
+
+// setStatus сохраняет первую неудачу: в строке из нескольких команд важна причина, по которой всё пошло не так.
+func (s *State) setStatus(status Status, code string) {
+	if s.result == nil || s.result.Status != StatusOK {
+		return
+	}
+	s.result.Status = status
+	s.result.Code = code
+}
+
+func (s *State) addDelta(delta Delta) {
+	if s.result != nil {
+		s.result.Deltas = append(s.result.Deltas, delta)
+	}
+}
+
+// itemMoved записывает перемещение предмета парой изменений; пустое to - предмет исчез (съеден).
+func (s *State) itemMoved(item *entity.Item, from, to string) {
+	s.addDelta(Delta{Kind: DeltaItemRemoved, Subject: item.Name, Key: from})
+	if to != "" {
+		s.addDelta(Delta{Kind: DeltaItemAdded, Subject: item.Name, Key: to})
+	}
+}
+
+// handleDeltaEvent переводит события мира в изменения для Result.
+// Перемещения предметов записывают сами команды: в событиях нет места, откуда предмет взят.
+func (s *State) handleDeltaEvent(event *entity.Event) error {
+	if s.result == nil || event.Prevented || event.Probe {
+		return nil
+	}
+
+	switch event.Type {
+	case entity.EventAfterMove:
+		if move, ok := entity.PayloadOf[entity.MovePayload](event); ok {
+			s.addDelta(Delta{Kind: DeltaMoved, Subject: SubjectPlayer, Key: move.From.Name, Value: move.To.Name})
+		}
+	case entity.EventDoorOpened:
+		s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: eventItemName(event), Key: "is_open", Value: true})
+	case entity.EventFurnitureOpened, entity.EventFurnitureClosed:
+		if payload, ok := entity.PayloadOf[entity.FurniturePayload](event); ok {
+			s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: payload.Furniture.Name(), Key: "opened", Value: payload.Furniture.Opened})
+		}
+	case entity.EventItemRevealed:
+		s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: eventItemName(event), Key: "hidden", Value: false})
+	case entity.EventLightOut:
+		s.addDelta(Delta{Kind: DeltaTraitChanged, Subject: eventItemName(event), Key: "lit", Value: false})
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"github.com/AgDecode/mini-game/entity"
+)
+
+// Правила мира отменяют действие, возвращая непустое сообщение для игрока.
+type MoveRule func(*State, entity.MovePayload) string
+
+type PickupRule func(*State, entity.ItemPayload) string
+
+const rulePriority = 100
+
+func (s *State) BlockMove(rule MoveRule) entity.Subscription {
+	return s.EventEmitter.OnPriority(entity.EventBeforeMove, rulePriority, func(event *entity.Event) error {
+		payload, ok := entity.PayloadOf[entity.MovePayload](event)
+		if !ok {
+			return nil
+		}
+		if message := rule(s, payload); message != "" {
+			event.Prevent(message)
+		}
+		return nil
+	})
+}
+
+func (s *State) BlockPickup(rule PickupRule) entity.Subscription {
+	return s.EventEmitter.OnPriority(entity.EventBeforePickup, rulePriority, func(event *entity.Event) error {
+		payload, ok := entity.PayloadOf[entity.ItemPayload](event)
+		if !ok {
+			return nil
+		}
+		if message := rule(s, payload); message != "" {
+			event.Prevent(message)
+		}
+		return nil
+	})
+}
+
+func registerWorldRules(state *State) {
+	state.BlockMove(func(s *State, move entity.MovePayload) string {
+		if reason, locked := move.From.LockReason(move.To); locked {
+			return reason
+		}
+		return ""
+	})
+
+	state.BlockMove(func(s *State, move entity.MovePayload) string {
+		if !s.CanSee(move.From) && !s.Player.HasVisited(move.To) {
+			return MsgTooDarkToGo
+		}
+		return ""
+	})
+
+	state.BlockMove(func(s *State, move entity.MovePayload) string {
+		if s.Player.HasEffect(EffectExhausted) {
+			return MsgNoEnergy
+		}
+		return ""
+	})
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgScore          = "очков: %d, достижения: %s"
+	MsgNoAchievements = "нет"
+)
+
+type ScoreRule struct {
+	Event  entity.EventType
+	Points int
+	Match  func(*State, *entity.Event) bool
+	Key    func(*entity.Event) string
+}
+
+type Achievement struct {
+	ID        string
+	Title     string
+	Condition func(*State, *entity.Event) bool
+}
+
+type AchievementPayload struct {
+	Achievement Achievement
+}
+
+type ScoringEngine struct {
+	Total        int
+	Rules        []ScoreRule
+	Achievements []Achievement
+	awarded      map[string]bool
+}
+
+func NewScoringEngine() *ScoringEngine {
+	return &ScoringEngine{
+		Rules:        make([]ScoreRule, 0),
+		Achievements: make([]Achievement, 0),
+		awarded:      make(map[string]bool),
+	}
+}
+
+func (s *State) RegisterScoreRule(rule ScoreRule) {
+	s.Scoring.Rules = append(s.Scoring.Rules, rule)
+	s.EventEmitter.On(rule.Event, func(event *entity.Event) error {
+		s.applyScoreRule(rule, event)
+		return nil
+	})
+}
+
+func (s *State) RegisterAchievement(achievement Achievement) {
+	s.Scoring.Achievements = append(s.Scoring.Achievements, achievement)
+}
+
+func (s *State) applyScoreRule(rule ScoreRule, event *entity.Event) {
+	if rule.Match != nil && !rule.Match(s, event) {
+		return
+	}
+	if rule.Key != nil {
+		key := string(rule.Event) + ":" + rule.Key(event)
+		if s.Scoring.awarded[key] {
+			return
+		}
+		s.Scoring.awarded[key] = true
+	}
+	s.Scoring.Total += rule.Points
+}
+
+func (s *State) handleAchievementEvent(event *entity.Event) error {
+	if event.Probe {
+		return nil
+	}
+	for _, achievement := range s.Scoring.Achievements {
+		if s.HasAchievement(achievement.ID) || !achievement.Condition(s, event) {
+			continue
+		}
+		s.unlockAchievement(achievement)
+	}
+	return nil
+}
+
+func (s *State) unlockAchievement(achievement Achievement) {
+	s.Scoring.awarded["achievement:"+achievement.ID] = true
+	if s.Profile != nil {
+		s.Profile.Unlock(achievement.ID)
+		err := s.saveProfile()
+		if err != nil {
+			return
+		}
+	}
+
+	event := entity.NewEvent(entity.EventAchievementUnlocked, s.Player, achievement.ID, AchievementPayload{Achievement: achievement})
+	err := s.EventEmitter.Emit(event)
+	if err != nil {
+		return
+	}
+}
+
+func (s *State) HasAchievement(id string) bool {
+	if s.Scoring.awarded["achievement:"+id] {
+		return true
+	}
+	return s.Profile != nil && s.Profile.HasAchievement(id)
+}
+
+func (s *State) Score() int {
+	return s.Scoring.Total
+}
+
+func (s *State) handleScore() string {
+	titles := []string{}
+	for _, achievement := range s.Scoring.Achievements {
+		if s.HasAchievement(achievement.ID) {
+			titles = append(titles, achievement.Title)
+		}
+	}
+
+	achievements := MsgNoAchievements
+	if len(titles) > 0 {
+		achievements = strings.Join(titles, ", ")
+	}
+	return fmt.Sprintf(MsgScore, s.Score(), achievements)
+}
+
+func eventItemName(event *entity.Event) string {
+	if payload, ok := entity.PayloadOf[entity.ItemPayload](event); ok {
+		return payload.Item.Name
+	}
+	if payload, ok := entity.PayloadOf[entity.UsePayload](event); ok {
+		if target, ok := payload.Target.(*entity.Item); ok {
+			return target.Name
+		}
+	}
+	return ""
+}
+
+func eventRoomName(event *entity.Event) string {
+	if payload, ok := entity.PayloadOf[entity.MovePayload](event); ok {
+		return payload.To.Name
+	}
+	return ""
+}
+
+func eventObjectiveTitle(event *entity.Event) string {
+	if payload, ok := entity.PayloadOf[ObjectivePayload](event); ok {
+		return payload.Objective.Title
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgFound        = "ты нашёл: %s"
+	MsgNothingFound = "ничего не найдено"
+	MsgStillLooking = "ты ищешь, но пока ничего не нашёл"
+)
+
+// Hiding описывает тайник: сколько раз нужно обыскать место и при каком условии поиск возможен.
+type Hiding struct {
+	Turns     int
+	Condition func(*State) string // Непустое сообщение - искать пока нельзя
+	progress  int
+}
+
+// Hide кладёт предмет в комнату спрятанным; без Hide предмет виден сразу.
+func (s *State) Hide(room *entity.Room, item *entity.Item, place string, hiding Hiding) {
+	item.Hide()
+	room.AddItem(item, place)
+	if s.Hidings == nil {
+		s.Hidings = make(map[*entity.Item]*Hiding)
+	}
+	s.Hidings[item] = &hiding
+}
+
+// handleSearch обыскивает одно место или, если оно не указано, всю комнату.
+func (s *State) handleSearch(name string) string {
+	room := s.Player.CurrentRoom
+
+	if !s.CanSee(room) {
+		return s.fail(CodeTooDark, MsgTooDark)
+	}
+
+	places := room.Places
+	if name != "" {
+		furniture := room.FindFurniture(name)
+		if furniture == nil {
+			return s.fail(CodeNotFound, MsgItemNotFound)
+		}
+		if !furniture.ContentsVisible() {
+			return s.fail(CodeClosed, fmt.Sprintf(MsgOpenFirst, furniture.Form(entity.Accusative)))
+		}
+		places = []string{furniture.Place()}
+	}
+
+	hidden := hiddenItems(room, places)
+	if len(hidden) == 0 {
+		return MsgNothingFound
+	}
+
+	var found []string
+	for _, item := range hidden {
+		hiding := s.Hidings[item]
+		if hiding == nil {
+			hiding = &Hiding{}
+		}
+		if hiding.Condition != nil {
+			if message := hiding.Condition(s); message != "" {
+				return s.block(message)
+			}
+		}
+		hiding.progress++
+		if hiding.progress >= hiding.Turns {
+			item.Reveal(s.Player)
+			found = append(found, item.Name)
+		}
+	}
+
+	if len(found) == 0 {
+		return MsgStillLooking
+	}
+	return fmt.Sprintf(MsgFound, strings.Join(found, ", "))
+}
+
+// hiddenItems возвращает ещё не найденные предметы; в закрытой мебели искать нельзя.
+func hiddenItems(room *entity.Room, places []string) []*entity.Item {
+	var hidden []*entity.Item
+	for _, place := range places {
+		if !room.IsVisible(place) {
+			continue
+		}
+		for _, item := range room.Items[place] {
+			if item.IsHidden() {
+				hidden = append(hidden, item)
+			}
+		}
+	}
+	return hidden
+}
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"text/template"
+
 	"github.com/AgDecode/mini-game/entity"
 )
 
@@ -15,13 +17,34 @@ type InteractionRule struct {
 }
 
 type State struct {
-	Player           *entity.Player
-	Rooms            map[string]*entity.Room
-	DoorOpened       bool
-	LastCommand      string
-	EventEmitter     *entity.EventEmitter
-	Commands         map[string]CommandHandler
-	InteractionRules []InteractionRule
+	Player             *entity.Player
+	Rooms              map[string]*entity.Room
+	DoorOpened         bool
+	EventEmitter       *entity.EventEmitter
+	Commands           map[string]CommandHandler
+	CommandInfos       map[string]*CommandInfo
+	InteractionRules   []InteractionRule
+	Clock              Clock
+	Scheduler          *Scheduler
+	Turn               int
+	UniversityClosed   bool
+	Quest              *Quest
+	Won                bool
+	TerminalConditions []TerminalCondition
+	GameOver           bool
+	Ending             *TerminalCondition
+	Scoring            *ScoringEngine
+	Profile            *Profile
+	ProfilePath        string
+	EventLog           *entity.EventLog
+	Recorder           *Recorder
+	Items              []*entity.Item
+	StartRoom          *entity.Room
+	Hidings            map[*entity.Item]*Hiding
+	Aliases            map[string]string
+	templates          map[string]*template.Template
+	result             *Result  // Результат выполняемой сейчас строки команд
+	notices            []string // Что случилось за ход помимо команды; дописывается к ответу
 }
 
 func NewState() *State {
@@ -29,17 +52,30 @@ func NewState() *State {
 		Rooms:            make(map[string]*entity.Room),
 		EventEmitter:     entity.NewEventEmitter(),
 		Commands:         make(map[string]CommandHandler),
+		CommandInfos:     make(map[string]*CommandInfo),
 		InteractionRules: make([]InteractionRule, 0),
+		Clock:            NewTurnClock(DefaultStartTime, DefaultTurnLength),
+		Scheduler:        NewScheduler(),
+		Scoring:          NewScoringEngine(),
 	}
 
 	return state
 }
 
-func (s *State) RegisterCommand(command string, handler CommandHandler) {
+// RegisterCommand регистрирует команду под именем command и под всеми info.Aliases.
+func (s *State) RegisterCommand(command string, info CommandInfo, handler CommandHandler) {
 	if s.Commands == nil {
 		s.Commands = make(map[string]CommandHandler)
 	}
-	s.Commands[command] = handler
+	if s.CommandInfos == nil {
+		s.CommandInfos = make(map[string]*CommandInfo)
+	}
+
+	info.Name = command
+	for _, name := range append([]string{command}, info.Aliases...) {
+		s.Commands[name] = handler
+		s.CommandInfos[name] = &info
+	}
 }
 
 func (s *State) RegisterInteractionRule(rule InteractionRule) {
@@ -84,9 +120,11 @@ func (s *State) CheckInteraction(source, target *entity.Item) (bool, Interaction
 func (s *State) ApplyInteraction(source, target *entity.Item) string {
 	canInteract, rule := s.CheckInteraction(source, target)
 	if !canInteract {
-		return "нельзя применить"
+		return s.fail(CodeNotApplicable, "нельзя применить")
 	}
 
+	source.Use(target)
+
 	if rule.StateModifier != nil {
 		rule.StateModifier(s, source, target)
 	}
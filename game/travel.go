@@ -0,0 +1,137 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgAlreadyThere = "ты уже здесь"
+	MsgUnknownRoute = "не знаю, как дойти до %s"
+	MsgRoute        = "путь: %s"
+	MsgNoTarget     = "не указано, до какой комнаты идти"
+)
+
+// noteworthyEvents прерывают переход через несколько комнат, чтобы игрок их не пропустил.
+var noteworthyEvents = []entity.EventType{
+	entity.EventObjectiveCompleted,
+	entity.EventAchievementUnlocked,
+	entity.EventItemRevealed,
+}
+
+// FindPath ищет кратчайший путь по известным игроку комнатам; с respectRules пропускает переходы, запрещённые правилами мира.
+func (s *State) FindPath(to *entity.Room, respectRules bool) []*entity.Room {
+	from := s.Player.CurrentRoom
+	known := s.knownRooms()
+
+	previous := map[*entity.Room]*entity.Room{from: nil}
+	queue := []*entity.Room{from}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+
+		if room == to {
+			var path []*entity.Room
+			for step := to; step != from; step = previous[step] {
+				path = append([]*entity.Room{step}, path...)
+			}
+			return path
+		}
+
+		for _, name := range sortedDirections(room) {
+			next := room.Connections[name]
+			if _, seen := previous[next]; seen || !known[next] {
+				continue
+			}
+			if respectRules {
+				if _, blocked := s.MoveBlocked(room, next); blocked {
+					continue
+				}
+			}
+			previous[next] = room
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// knownRooms - посещённые комнаты и те, куда из них видны выходы.
+func (s *State) knownRooms() map[*entity.Room]bool {
+	known := map[*entity.Room]bool{}
+	for _, room := range s.Rooms {
+		if !s.Player.HasVisited(room) {
+			continue
+		}
+		known[room] = true
+		for _, next := range room.Connections {
+			known[next] = true
+		}
+	}
+	return known
+}
+
+// handleGoTo ведёт игрока по кратчайшему пути; каждый переход - отдельный ход.
+func (s *State) handleGoTo(name string) string {
+	target := s.Rooms[name]
+	if target == nil || !s.knownRooms()[target] {
+		return s.fail(CodeNoPath, fmt.Sprintf(MsgUnknownRoute, name))
+	}
+	if target == s.Player.CurrentRoom {
+		return s.fail(CodeAlreadyDone, MsgAlreadyThere)
+	}
+
+	path := s.FindPath(target, true)
+	if path == nil {
+		// Путь есть, но его закрывает правило - идём, пока игрок сам в него не упрётся.
+		path = s.FindPath(target, false)
+	}
+	if path == nil {
+		return s.fail(CodeNoPath, fmt.Sprintf(MsgUnknownRoute, name))
+	}
+
+	noteworthy := false
+	subscriptions := make([]entity.Subscription, 0, len(noteworthyEvents))
+	for _, eventType := range noteworthyEvents {
+		subscriptions = append(subscriptions, s.EventEmitter.On(eventType, func(event *entity.Event) error {
+			noteworthy = true
+			return nil
+		}))
+	}
+	defer func() {
+		for _, sub := range subscriptions {
+			s.EventEmitter.Off(sub)
+		}
+	}()
+
+	var passed []string
+	for i, next := range path {
+		if i > 0 {
+			s.tick()
+			s.checkTerminalConditions()
+			if s.GameOver {
+				return s.fail(CodeGameOver, s.gameOverSummary())
+			}
+		}
+
+		answer := s.handleGo(next.Name)
+		if s.Player.CurrentRoom != next {
+			return routeAnswer(path, passed, answer)
+		}
+		passed = append(passed, next.Name)
+
+		if i == len(path)-1 || noteworthy || !s.CanSee(next) {
+			return routeAnswer(path, passed, answer)
+		}
+	}
+	return ""
+}
+
+// routeAnswer добавляет пройденный путь, если переходов было больше одного.
+func routeAnswer(path []*entity.Room, passed []string, answer string) string {
+	if len(path) < 2 || len(passed) == 0 {
+		return answer
+	}
+	return fmt.Sprintf(MsgRoute, strings.Join(passed, ", ")) + ". " + answer
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AgDecode/mini-game/entity"
+)
+
+const (
+	MsgMapEmpty     = "карта пуста"
+	MapHere         = " <- ты здесь"
+	MapNotExplored  = " (?)"
+	mapBranch       = "├── "
+	mapLastBranch   = "└── "
+	mapIndent       = "│   "
+	mapLastIndent   = "    "
+	dotLockedStyle  = "style=dashed, color=red"
+	dotDefaultShape = "box"
+)
+
+// MoveBlocked спрашивает обработчики before_move, пустили бы они игрока сейчас, не двигая его.
+func (s *State) MoveBlocked(from, to *entity.Room) (string, bool) {
+	payload := entity.MovePayload{From: from, To: to, Direction: from.DirectionTo(to)}
+	return s.EventEmitter.Probe(entity.EventBeforeMove, s.Player, to, payload)
+}
+
+// RenderDOT описывает топологию мира на языке Graphviz.
+func RenderDOT(s *State) string {
+	var out strings.Builder
+	out.WriteString("digraph world {\n")
+	out.WriteString(fmt.Sprintf("\tnode [shape=%s];\n", dotDefaultShape))
+
+	for _, room := range sortedRooms(s) {
+		attrs := []string{}
+		if tooltip := roomItemsSummary(room); tooltip != "" {
+			attrs = append(attrs, "tooltip="+strconv.Quote(tooltip))
+		}
+		if room == s.Player.CurrentRoom {
+			attrs = append(attrs, "penwidth=2")
+		}
+		out.WriteString("\t" + strconv.Quote(room.Name))
+		if len(attrs) > 0 {
+			out.WriteString(" [" + strings.Join(attrs, ", ") + "]")
+		}
+		out.WriteString(";\n")
+	}
+
+	for _, room := range sortedRooms(s) {
+		for _, direction := range sortedDirections(room) {
+			next := room.Connections[direction]
+			var attrs []string
+			if compass := room.DirectionTo(next); compass != "" {
+				attrs = append(attrs, "label="+strconv.Quote(compass))
+			}
+			// Только постоянные запоры: временные запреты (темнота, усталость) зависят от игрока, а не от карты.
+			if reason, locked := room.LockReason(next); locked {
+				attrs = append(attrs, dotLockedStyle, "tooltip="+strconv.Quote(reason))
+			}
+			out.WriteString(fmt.Sprintf("\t%s -> %s", strconv.Quote(room.Name), strconv.Quote(next.Name)))
+			if len(attrs) > 0 {
+				out.WriteString(" [" + strings.Join(attrs, ", ") + "]")
+			}
+			out.WriteString(";\n")
+		}
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// RenderASCIIMap рисует дерево комнат от стартовой; при onlyVisited непосещённые комнаты
+// показываются только как выходы, без дальнейших переходов.
+func RenderASCIIMap(s *State, onlyVisited bool) string {
+	root := s.StartRoom
+	if root == nil || (onlyVisited && !s.Player.HasVisited(root)) {
+		return MsgMapEmpty
+	}
+
+	var lines []string
+	seen := map[*entity.Room]bool{root: true}
+	lines = append(lines, root.Name+mapMarker(s, root, onlyVisited))
+
+	var walk func(room *entity.Room, prefix string)
+	walk = func(room *entity.Room, prefix string) {
+		var children []*entity.Room
+		for _, direction := range sortedDirections(room) {
+			next := room.Connections[direction]
+			if !seen[next] {
+				seen[next] = true
+				children = append(children, next)
+			}
+		}
+
+		for i, child := range children {
+			branch, indent := mapBranch, mapIndent
+			if i == len(children)-1 {
+				branch, indent = mapLastBranch, mapLastIndent
+			}
+			lines = append(lines, prefix+branch+child.Name+mapMarker(s, child, onlyVisited))
+			if !onlyVisited || s.Player.HasVisited(child) {
+				walk(child, prefix+indent)
+			}
+		}
+	}
+	walk(root, "")
+
+	return strings.Join(lines, "\n")
+}
+
+func mapMarker(s *State, room *entity.Room, onlyVisited bool) string {
+	if room == s.Player.CurrentRoom {
+		return MapHere
+	}
+	if onlyVisited && !s.Player.HasVisited(room) {
+		return MapNotExplored
+	}
+	return ""
+}
+
+func sortedRooms(s *State) []*entity.Room {
+	rooms := make([]*entity.Room, 0, len(s.Rooms))
+	for _, room := range s.Rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Name < rooms[j].Name
+	})
+	return rooms
+}
+
+func roomItemsSummary(room *entity.Room) string {
+	places := make([]string, 0, len(room.Items))
+	for place, items := range room.Items {
+		if len(items) > 0 {
+			places = append(places, fmt.Sprintf("%s %s: %s", placePreposition(room, place), place, strings.Join(itemNames(items), ", ")))
+		}
+	}
+	sort.Strings(places)
+	return strings.Join(places, "; ")
+}
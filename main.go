@@ -1,10 +1,131 @@
 package main
 
 import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/AgDecode/mini-game/entity"
 	"github.com/AgDecode/mini-game/game"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
+	profilePath := flag.String("profile", "", "файл профиля игрока с достижениями")
+	playerName := flag.String("name", "игрок", "имя игрока")
+	eventsPath := flag.String("events", "", "файл журнала событий (JSON Lines)")
+	recordPath := flag.String("record", "", "файл для записи команд и ответов для replay")
+	flag.Parse()
+
+	initGame()
+
+	if *profilePath != "" {
+		profile, err := game.LoadProfile(*profilePath, *playerName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		gameState.AttachProfile(profile, *profilePath)
+	}
+
+	if *eventsPath != "" {
+		eventLog, err := entity.OpenEventLog(*eventsPath, "")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		gameState.AttachEventLog(eventLog)
+	}
+
+	if *recordPath != "" {
+		file, err := os.OpenFile(*recordPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		gameState.AttachRecorder(game.NewRecorder(file))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	var readLine func() (string, error)
+	if restore, err := enableRawMode(os.Stdin); err == nil {
+		restoreTerminal = restore
+		editor := &lineEditor{
+			in:       bufio.NewReader(os.Stdin),
+			out:      os.Stdout,
+			prompt:   "> ",
+			complete: gameState.Complete,
+		}
+		readLine = editor.ReadLine
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		readLine = func() (string, error) {
+			if !scanner.Scan() {
+				return "", io.EOF
+			}
+			return scanner.Text(), nil
+		}
+	}
+
+	run(readLine, signals)
+	shutdown()
+}
+
+// run выполняет команды до конца ввода или сигнала. Состояние игры трогает только эта горутина:
+// чтение идёт в отдельной, но следующая строка (и дополнение по Tab) читается лишь после ответа.
+func run(readLine func() (string, error), signals <-chan os.Signal) {
+	lines := make(chan string)
+	next := make(chan struct{})
+	go func() {
+		defer close(lines)
+		for {
+			line, err := readLine()
+			if err != nil {
+				return
+			}
+			lines <- line
+			if _, ok := <-next; !ok {
+				return
+			}
+		}
+	}()
+	defer close(next)
+
+	for {
+		select {
+		case <-signals:
+			return
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			fmt.Println(handleCommand(line))
+			next <- struct{}{}
+		}
+	}
+}
+
+var restoreTerminal func()
+
+func shutdown() {
+	if restoreTerminal != nil {
+		restoreTerminal()
+	}
+	if err := gameState.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
 
 var gameState *game.State
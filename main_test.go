@@ -1,7 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/AgDecode/mini-game/entity"
+	"github.com/AgDecode/mini-game/game"
 )
 
 type gameCase struct {
@@ -51,6 +58,34 @@ var game0cases = [][]gameCase{
 		{24, "применить ключи шкаф", "не к чему применить"},                  // предмет есть, но применить его к этому нельзя
 		{25, "идти улица", "на улице весна. можно пройти - домой"},
 	},
+
+	{
+		{1, "задания", "задания: надеть рюкзак, взять конспекты, дойти до универа"},
+		{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{4, "надеть рюкзак", "вы надели: рюкзак"},
+		{5, "задания", "задания: надеть рюкзак (выполнено), взять конспекты, дойти до универа"}, // прогресс по событиям
+		{6, "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{7, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{8, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{9, "применить ключи дверь", "дверь открыта"},
+		{10, "счёт", "очков: 80, достижения: взломщик, отличник"},
+		{11, "идти улица", "на улице весна. можно пройти - домой"},
+		{12, "задания", "игра окончена: ты дошёл до универа. ходов: 11, очков: 105, предметы: рюкзак, конспекты, ключи. чтобы начать сначала - заново"},
+		{13, "х = время", "игра окончена: ты дошёл до универа. ходов: 11, очков: 105, предметы: рюкзак, конспекты, ключи. чтобы начать сначала - заново"}, // и алиас не задать
+		{14, "заново", "игра начата заново"},
+		{15, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+		{16, "алиасы", "алиасов нет"},
+	},
+
+	{
+		{1, "состояние", "здоровье: 100, энергия: 60, голод: 30, состояние: в порядке"},
+		{2, "есть чай", "это нельзя есть"},
+		{3, "пить чай", "ты выпил: чай"}, // всё-таки позавтракал
+		{4, "состояние", "здоровье: 100, энергия: 80, голод: 12, состояние: сыт"},
+		{5, "осмотреться", "ты находишься на кухне, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+		{6, "пить чай", "нет такого"},
+	},
 }
 
 func TestGame0(t *testing.T) {
@@ -68,3 +103,670 @@ func TestGame0(t *testing.T) {
 	}
 
 }
+
+func checkCases(t *testing.T, cases []gameCase) {
+	t.Helper()
+	for _, item := range cases {
+		answer := handleCommand(item.command)
+		if answer != item.answer {
+			t.Error("step:", item.step,
+				"\n\tcmd:", item.command,
+				"\n\tresult:  ", answer,
+				"\n\texpected:", item.answer)
+		}
+	}
+}
+
+var timeCases = []gameCase{
+	{1, "время", "сейчас 08:58, утро"},
+	{2, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+	{3, "время", "сейчас 09:00, утро, универ уже закрыт"}, // сработало событие по расписанию
+}
+
+func TestGameTime(t *testing.T) {
+	initGame()
+	gameState.SetClock(game.NewTurnClock(game.DefaultStartTime.Add(58*time.Minute), time.Minute))
+	checkCases(t, timeCases)
+
+	initGame()
+	gameState.SetClock(game.NewTurnClock(game.DefaultStartTime.Add(14*time.Minute), time.Minute))
+	checkCases(t, teaCases)
+
+	initGame()
+	gameState.SetClock(game.NewTurnClock(game.DefaultStartTime.Add(11*time.Hour), time.Minute))
+	checkCases(t, eveningCases)
+
+	// События мира переезжают вместе с часами на другой день.
+	initGame()
+	gameState.SetClock(game.NewTurnClock(game.DefaultStartTime.AddDate(0, 0, 1), time.Minute))
+	checkCases(t, []gameCase{{1, "время", "сейчас 08:00, утро"}, {2, "время", "сейчас 08:01, утро"}})
+
+	initGame()
+	gameState.Rooms["кухня"].TimeMessages["утро"] = "утро на кухне. можно пройти - коридор"
+	checkCases(t, []gameCase{{1, "осмотреться", "утро на кухне. можно пройти - коридор"}}) // и при осмотре, не только при входе
+
+	initGame()
+	for gameState.Turn < 62 {
+		gameState.HandleCommand("время")
+	}
+	checkCases(t, []gameCase{
+		{1, "время", "сейчас 09:02, утро, универ уже закрыт"},
+		{2, "заново", "игра начата заново"},
+		{3, "время", "сейчас 08:00, утро"}, // после перезапуска снова утро
+	})
+}
+
+var teaCases = []gameCase{
+	{1, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+	{2, "пить чай", "ты выпил: чай, он уже остыл"}, // в 08:15 чай остыл
+}
+
+var eveningCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{3, "надеть рюкзак", "вы надели: рюкзак"},
+	{4, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+	{5, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{6, "применить ключи дверь", "дверь открыта"},
+	{7, "идти улица", "на улице вечереет. можно пройти - домой"}, // описание зависит от времени суток
+	{8, "заново", "игра начата заново"},
+	{9, "время", "сейчас 19:00, вечер"}, // подставленные часы начинают сначала
+}
+
+var rulesCases = []gameCase{
+	{1, "идти коридор", "нельзя уйти, не выпив чай"},
+	{2, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"}, // остались на месте
+	{3, "пить чай", "ты выпил: чай"},
+	{4, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{5, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{6, "надеть рюкзак", "рюкзак прибит к стулу"},
+	{7, "взять рюкзак", "рюкзак прибит к стулу"},
+	{8, "осмотреться", "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор"},
+	{9, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{10, "идти улица", "дверь закрыта"}, // проверка двери тоже правило мира
+}
+
+func TestWorldRules(t *testing.T) {
+	initGame()
+	gameState.BlockMove(func(s *game.State, move entity.MovePayload) string {
+		if move.From.Name == "кухня" && move.From.HasItem("чай") {
+			return "нельзя уйти, не выпив чай"
+		}
+		return ""
+	})
+	gameState.BlockPickup(func(s *game.State, pickup entity.ItemPayload) string {
+		if pickup.Item.Name == "рюкзак" && pickup.Place == "стуле" {
+			return "рюкзак прибит к стулу"
+		}
+		return ""
+	})
+
+	checkCases(t, rulesCases)
+
+	initGame()
+	gameState.BlockMove(func(s *game.State, move entity.MovePayload) string {
+		if move.To.Name == "улица" {
+			return "нельзя на улицу"
+		}
+		return ""
+	})
+	checkCases(t, vetoCases)
+
+	// Съесть или выпить со стола - то же, что взять
+	initGame()
+	gameState.BlockPickup(func(s *game.State, pickup entity.ItemPayload) string {
+		if pickup.Item.Name == "чай" {
+			return "чай слишком горячий"
+		}
+		return ""
+	})
+	checkCases(t, []gameCase{
+		{1, "пить чай", "чай слишком горячий"},
+		{2, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+	})
+}
+
+// Запрещённый переход не должен ничего менять: ни задания, ни очки.
+var vetoCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{3, "надеть рюкзак", "вы надели: рюкзак"},
+	{4, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+	{5, "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+	{6, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{7, "применить ключи дверь", "дверь открыта"},
+	{8, "счёт", "очков: 80, достижения: взломщик, отличник"},
+	{9, "идти улица", "нельзя на улицу"},
+	{10, "счёт", "очков: 80, достижения: взломщик, отличник"},
+	{11, "задания", "задания: надеть рюкзак (выполнено), взять конспекты (выполнено), дойти до универа"},
+	{12, "осмотреться", "на стене: дверь. можно пройти - комната, кухня, улица"},
+}
+
+func TestReplay(t *testing.T) {
+	var log bytes.Buffer
+	initGame()
+	gameState.AttachRecorder(game.NewRecorder(&log))
+	for _, item := range game0cases[1] {
+		handleCommand(item.command)
+	}
+
+	recording, err := game.LoadRecording(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, divergence := game.Replay(recording, len(recording.Steps)); divergence != nil {
+		t.Fatal("replay diverged:", divergence)
+	}
+
+	recording.Steps[13].Reply = "на столе: ключи. можно пройти - коридор" // как будто движок изменился
+	_, divergence := game.Replay(recording, len(recording.Steps))
+	if divergence == nil || divergence.Step != 14 {
+		t.Fatal("replay found:", divergence)
+	}
+
+	// Алиасы и достижения профиля попадают в заголовок, отменённые действия - в события шага.
+	log.Reset()
+	initGame()
+	profile := game.NewProfile("тест")
+	profile.Aliases = map[string]string{"к": "идти коридор"}
+	profile.Unlock("locksmith")
+	gameState.AttachProfile(profile, "")
+	gameState.AttachRecorder(game.NewRecorder(&log))
+	for _, command := range []string{"к", "идти улица", "идти комната", "надеть рюкзак", "взять ключи", "к", "применить ключи дверь", "счёт"} {
+		handleCommand(command)
+	}
+
+	recording, err = game.LoadRecording(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := recording.Steps[1].Events; len(events) == 0 || events[0] != entity.EventBeforeMove {
+		t.Error("запрещённый переход не записан:", events)
+	}
+	if _, divergence := game.Replay(recording, len(recording.Steps)); divergence != nil {
+		t.Fatal("replay with profile diverged:", divergence)
+	}
+}
+
+func TestExplore(t *testing.T) {
+	explorer := game.NewExplorer(10)
+
+	result := explorer.Explore()
+	for _, violation := range result.Violations {
+		t.Error(violation)
+	}
+	expected := []string{"идти коридор", "идти комната", "надеть рюкзак", "взять ключи", "взять конспекты", "идти коридор", "применить ключи дверь", "идти улица"}
+	if len(result.Solution) != len(expected) {
+		t.Error("shortest solution:", result.Solution, "\n\texpected length:", len(expected))
+	}
+
+	initGame()
+	for _, command := range result.Solution {
+		handleCommand(command)
+	}
+	if gameState.Outcome() != game.OutcomeWon {
+		t.Error("solution does not win:", result.Solution)
+	}
+
+	for _, violation := range explorer.Random(1, 50, 60) {
+		t.Error(violation)
+	}
+}
+
+var mapCases = []gameCase{
+	{1, "карта", "кухня <- ты здесь\n└── коридор (?)"},
+	{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{4, "карта", "кухня\n└── коридор\n    ├── комната <- ты здесь\n    └── улица (?)"},
+}
+
+func TestWorldMap(t *testing.T) {
+	initGame()
+	checkCases(t, mapCases)
+
+	dot := game.RenderDOT(gameState)
+	for _, expected := range []string{
+		`"комната" [tooltip="в шкафу: куртка, фонарик; на столе: ключи, конспекты; на стуле: рюкзак; под кроватью: деньги", penwidth=2];`,
+		`"коридор" -> "улица" [label="юг", style=dashed, color=red, tooltip="дверь закрыта"];`,
+		`"улица" -> "домой" [label="запад"];`,
+		`"подвал" -> "домой" [label="вверх"];`, // темнота в подвале - не запор
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Error("DOT has no line:", expected, "\n", dot)
+		}
+	}
+
+	gameState.Player.SetAttribute(entity.AttrEnergy, 0)
+	gameState.HandleCommand("время")
+	if !gameState.Player.HasEffect(game.EffectExhausted) {
+		t.Fatal("игрок не устал")
+	}
+	if dot := game.RenderDOT(gameState); strings.Count(dot, "style=dashed") != 1 {
+		t.Error("усталость игрока не запирает переходы на карте:\n", dot)
+	}
+
+	// Правило, подписанное прямо на шину, тоже учитывается при поиске пути.
+	gameState.EventEmitter.On(entity.EventBeforeMove, func(event *entity.Event) error {
+		event.Prevent("нельзя")
+		return nil
+	})
+	if path := gameState.FindPath(gameState.Rooms["кухня"], true); path != nil {
+		t.Error("путь в обход правила на шине:", path)
+	}
+}
+
+var lookCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "осмотреться", "на стене: дверь. можно пройти - комната, кухня, улица"},
+	{3, "идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+	{4, "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+}
+
+func TestLookTemplate(t *testing.T) {
+	initGame()
+	checkCases(t, lookCases)
+
+	gameState.Rooms["коридор"].LookTemplate = "{{range .Places}}{{.Name}}: {{join .Items \"+\"}}{{end}} ({{.TimeOfDay}})"
+	checkCases(t, []gameCase{{5, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"}, {6, "осмотреться", "стене: дверь (утро)"}})
+
+	gameState.Rooms["коридор"].LookTemplate = "{{.Broken"
+	checkCases(t, []gameCase{{7, "осмотреться", "на стене: дверь. можно пройти - комната, кухня, улица"}})
+}
+
+var furnitureCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{3, "осмотреть шкаф", "старый платяной шкаф, не видно, что в шкафу"},
+	{4, "взять куртка", "нет такого"},
+	{5, "осмотреть стол", "на столе: ключи, конспекты"},
+	{6, "открыть стол", "нельзя открыть стол"},
+	{7, "открыть шкаф", "ты открыл шкаф. в шкафу: куртка, фонарик"},
+	{8, "открыть шкаф", "уже открыто"},
+	{9, "осмотреться", "на столе: ключи, конспекты, на стуле: рюкзак, в шкафу: куртка, фонарик. можно пройти - коридор"},
+	{10, "надеть рюкзак", "вы надели: рюкзак"},
+	{11, "взять куртка", "предмет добавлен в инвентарь: куртка"},
+	{12, "осмотреть куртка", "тёплая куртка"},
+	{13, "осмотреть шкафу", "старый платяной шкаф, в шкафу: фонарик"},
+	{14, "закрыть шкаф", "ты закрыл шкаф"},
+	{15, "положить куртка в шкаф", "сначала надо открыть шкаф"},
+	{16, "положить куртка на стул", "предмет положен на стул: куртка"},
+	{17, "осмотреть стул", "на стуле: куртка"},
+	{18, "открыть дверь", "нет такого"},
+}
+
+func TestFurniture(t *testing.T) {
+	initGame()
+	checkCases(t, furnitureCases)
+}
+
+var searchCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{3, "надеть рюкзак", "вы надели: рюкзак"},
+	{4, "взять деньги", "нет такого"},
+	{5, "обыскать стол", "ничего не найдено"},
+	{6, "обыскать шкаф", "сначала надо открыть шкаф"},
+	{7, "обыскать под кроватью", "без света тут ничего не найти"},
+	{8, "открыть шкаф", "ты открыл шкаф. в шкафу: куртка, фонарик"},
+	{9, "взять фонарик", "предмет добавлен в инвентарь: фонарик"},
+	{10, "включить фонарик", "фонарик горит"},
+	{11, "обыскать под кроватью", "ты ищешь, но пока ничего не нашёл"},
+	{12, "осмотреть кровать", "под кроватью пусто"},
+	{13, "искать", "ты нашёл: деньги"},
+	{14, "осмотреться", "на столе: ключи, конспекты, в шкафу: куртка, под кроватью: деньги. можно пройти - коридор"},
+	{15, "взять деньги", "предмет добавлен в инвентарь: деньги"},
+	{16, "искать", "ничего не найдено"},
+}
+
+func TestSearch(t *testing.T) {
+	initGame()
+	checkCases(t, searchCases)
+}
+
+var darkCases = []gameCase{
+	{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+	{3, "надеть рюкзак", "вы надели: рюкзак"},
+	{4, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+	{5, "открыть шкаф", "ты открыл шкаф. в шкафу: куртка, фонарик"},
+	{6, "взять фонарик", "предмет добавлен в инвентарь: фонарик"},
+	{7, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{8, "применить ключи дверь", "дверь открыта"},
+	{9, "идти улица", "на улице весна. можно пройти - домой"},
+	{10, "задания", "задания: надеть рюкзак (выполнено), взять конспекты, дойти до универа"}, // без конспектов до универа не дойти
+	{11, "идти домой", "ты дома. можно пройти - улица, подвал"},
+	{12, "взять свеча", "предмет добавлен в инвентарь: свеча"},
+	{13, "идти подвал", "слишком темно, ничего не видно"},
+	{14, "осмотреться", "слишком темно, ничего не видно"},
+	{15, "взять варенье", "слишком темно, ничего не видно"},
+	{16, "есть варенье", "нет такого"},
+	{17, "включить ключи", "нельзя зажечь ключи"},
+	{18, "зажечь свеча", "свеча горит. на полке: варенье. в животе урчит. можно пройти - домой"},
+	{19, "взять варенье", "предмет добавлен в инвентарь: варенье"},
+	{20, "потушить свеча", "свеча не горит"},
+	{21, "потушить свеча", "и так не горит"},
+	{22, "идти домой", "ты дома. можно пройти - улица, подвал"},
+}
+
+func TestDarkness(t *testing.T) {
+	initGame()
+	checkCases(t, darkCases)
+
+	candle := gameState.Player.GetItem("свеча")
+	candle.LightSource().Fuel = 1
+	checkCases(t, []gameCase{
+		{22, "зажечь свеча", "свеча горит. свеча догорает и гаснет"},
+		{23, "зажечь свеча", "свеча больше не светит"},
+	})
+
+	// Догоревшая в тёмной комнате свеча оставляет игрока в темноте
+	lightsOut := 0
+	gameState.EventEmitter.On(entity.EventLightOut, func(event *entity.Event) error {
+		lightsOut++
+		return nil
+	})
+	candle.LightSource().Fuel = 2
+	checkCases(t, []gameCase{
+		{24, "идти подвал", "слишком темно, ничего не видно"},
+		{25, "зажечь свеча", "свеча горит. пустая комната. в животе урчит. можно пройти - домой"},
+		{26, "осмотреться", "пустая комната. в животе урчит. можно пройти - домой. свеча догорает и гаснет. слишком темно, ничего не видно"},
+		{27, "осмотреться", "слишком темно, ничего не видно"},
+	})
+	if lightsOut != 1 {
+		t.Errorf("событий light_out: %d, ожидалось 1", lightsOut)
+	}
+}
+
+var compassCases = []gameCase{
+	{1, "идти север", "в ту сторону не пройти"},
+	{2, "ю", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{3, "с", "кухня, ничего интересного. можно пройти - коридор"},
+	{4, "идти юг", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{5, "компас", "выходы: со сторонами света"},
+	{6, "осмотреться", "на стене: дверь. можно пройти - комната (восток), кухня (север), улица (юг)"},
+	{7, "восток", "ты в своей комнате. можно пройти - коридор"},
+	{8, "идти запад", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{9, "юг", "дверь закрыта"},
+	{10, "компас", "выходы: по названиям"},
+	{11, "осмотреться", "на стене: дверь. можно пройти - комната, кухня, улица"},
+	{12, "вверх", "в ту сторону не пройти"},
+}
+
+func TestCompass(t *testing.T) {
+	initGame()
+	checkCases(t, compassCases)
+
+	street := gameState.Rooms["улица"]
+	if _, ok := street.Exit("север"); ok {
+		t.Error("с улицы не должно быть обратного пути в коридор")
+	}
+	if cellar, ok := gameState.Rooms["домой"].Exit("вниз"); !ok || cellar.DirectionTo(gameState.Rooms["домой"]) != "вверх" {
+		t.Error("обратный переход из подвала не выведен")
+	}
+
+	var directions []string
+	gameState.BlockMove(func(s *game.State, move entity.MovePayload) string {
+		directions = append(directions, move.Direction)
+		return "стоп"
+	})
+	for _, command := range []string{"идти кухня", "идти север", "с", "север", "идти комната", "в"} {
+		gameState.HandleCommand(command)
+	}
+	if expected := "север север север север восток восток"; strings.Join(directions, " ") != expected {
+		t.Errorf("направления %q, ожидалось %q", directions, expected)
+	}
+}
+
+var goToCases = []gameCase{
+	{1, "идти до улица", "не знаю, как дойти до улица"},
+	{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{3, "идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+	{4, "идти до кухня", "ты уже здесь"},
+	{5, "идти до комната", "путь: коридор, комната. ты в своей комнате. можно пройти - коридор"},
+	{6, "время", "сейчас 08:06, утро"}, // по ходу на каждый переход
+	{7, "надеть рюкзак", "вы надели: рюкзак"},
+	{8, "идти до улица", "путь: коридор. дверь закрыта"},
+	{9, "идти до комната", "ты в своей комнате. можно пройти - коридор"},
+	{10, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+	{11, "идти до кухня", "путь: коридор, кухня. кухня, ничего интересного. можно пройти - коридор"},
+	{12, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{13, "применить ключи дверь", "дверь открыта"},
+	{14, "идти до кухня", "кухня, ничего интересного. можно пройти - коридор"},
+	{15, "идти до подвал", "не знаю, как дойти до подвал"},
+	{16, "идти до улица", "путь: коридор, улица. на улице весна. можно пройти - домой"},
+	{17, "идти до", "не указано, до какой комнаты идти"},
+}
+
+func TestGoTo(t *testing.T) {
+	initGame()
+	checkCases(t, goToCases)
+
+	initGame()
+	checkCases(t, []gameCase{
+		{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{3, "идти до кухня", "путь: коридор, кухня. кухня, ничего интересного. можно пройти - коридор"},
+	})
+	gameState.SetQuest(game.NewQuest("обход", &game.Objective{
+		Title: "заглянуть в коридор",
+		Condition: func(s *game.State) bool {
+			return s.Player.CurrentRoom.Name == "коридор"
+		},
+	}))
+	checkCases(t, []gameCase{
+		{4, "идти до комната", "путь: коридор. ничего интересного. можно пройти - кухня, комната, улица"}, // задание выполнено - останавливаемся
+	})
+}
+
+var aliasCases = []gameCase{
+	{1, "алиасы", "алиасов нет"},
+	{2, "алиас в = идти", "алиас задан: в = идти"},
+	{3, "в коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+	{4, "утро = в комната; надеть рюкзак; взять ключи", "алиас задан: утро = в комната; надеть рюкзак; взять ключи"},
+	{5, "утро", "ты в своей комнате. можно пройти - коридор\nвы надели: рюкзак\nпредмет добавлен в инвентарь: ключи"},
+	{6, "время", "сейчас 08:05, утро"}, // каждая команда макроса - отдельный ход
+	{7, "идти коридор; применить ключи дверь", "ничего интересного. можно пройти - кухня, комната, улица\nдверь открыта"},
+	{8, "идти = идти кухня", "алиас задан: идти = идти кухня"},
+	{9, "идти", "кухня, ничего интересного. можно пройти - коридор"}, // внутри алиаса имя снова означает команду
+	{10, "петля = петля", "алиас задан: петля = петля"},
+	{11, "петля", "алиас петля вызывает сам себя"},
+	{12, "пинг = понг", "алиас задан: пинг = понг"},
+	{13, "понг = пинг; пинг", "алиас задан: понг = пинг; пинг"},
+	{14, "понг", "алиас понг вызывает сам себя\nалиас понг вызывает сам себя"},
+	{15, "алиасы", "алиасы: в = идти, идти = идти кухня, петля = петля, пинг = понг, понг = пинг; пинг, утро = в комната; надеть рюкзак; взять ключи"},
+	{16, "алиас идти =", "алиас удалён: идти"},
+	{17, "алиас алиасы = время", "нельзя переопределить команду алиасы"},
+	{18, "; ;", "неизвестная команда"},
+	{19, "заново", "игра начата заново"},
+	{20, "в коридор", "ничего интересного. можно пройти - кухня, комната, улица"}, // алиасы переживают рестарт
+}
+
+func TestAliases(t *testing.T) {
+	initGame()
+	checkCases(t, aliasCases)
+
+	checkCases(t, []gameCase{
+		{21, "много = время; время; время; время", "алиас задан: много = время; время; время; время"},
+		{22, "осмотреться; в = идти", "на стене: дверь. можно пройти - комната, кухня, улица\nалиас задан: в = идти"},
+	})
+	if answer := gameState.HandleCommand("много; много; много"); !strings.HasSuffix(answer, game.MsgTooManySteps) ||
+		strings.Count(answer, "\n") != game.MaxLineCommands {
+		t.Error("макросы не ограничены:", answer)
+	}
+
+	path := t.TempDir() + "/profile.json"
+	gameState.AttachProfile(game.NewProfile("тест"), path)
+	gameState.HandleCommand("алиас к = идти кухня")
+
+	profile, err := game.LoadProfile(path, "тест")
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := game.InitGame()
+	restored.AttachProfile(profile, path)
+	if answer := restored.HandleCommand("алиасы"); !strings.Contains(answer, "к = идти кухня") {
+		t.Error("алиасы не сохранились в профиле:", answer)
+	}
+}
+
+var helpCases = []gameCase{
+	{1, "помощь взять", "взять <предмет> - положить предмет из комнаты в рюкзак"},
+	{2, "помощь искать", "обыскать [место] - поискать спрятанное в мебели или во всей комнате. иначе: искать"},
+	{3, "помощь с", "север - идти север. иначе: с"},
+	{4, "помощь летать", "нет такой команды - летать"},
+	{5, "иди коридор", "неизвестная команда, может быть: идти?"},
+	{6, "осмотрется", "неизвестная команда, может быть: осмотреться?"},
+	{7, "завтракать", "неизвестная команда"},
+	{8, "утро = идти коридор", "алиас задан: утро = идти коридор"},
+	{9, "утор", "неизвестная команда, может быть: утро?"},
+}
+
+func TestHelp(t *testing.T) {
+	initGame()
+	checkCases(t, helpCases)
+
+	answer := gameState.HandleCommand("помощь")
+	for _, name := range []string{"идти", "взять", "помощь", "заново"} {
+		if !strings.Contains(answer, name) {
+			t.Errorf("в помощи нет команды %s: %s", name, answer)
+		}
+	}
+	if strings.Contains(answer, "север") {
+		t.Error("скрытые команды попали в помощь:", answer)
+	}
+
+	for name := range gameState.Commands {
+		if info := gameState.CommandInfo(name); info == nil || info.Description == "" {
+			t.Errorf("у команды %s нет описания", name)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	initGame()
+	cases := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"вз", []string{"взять"}},
+		{"осмотр", []string{"осмотреть", "осмотреться"}},
+		{"идти ", []string{"идти до", "идти коридор", "идти юг"}},
+		{"пить ч", []string{"пить чай"}},
+		{"пить чай; ид", []string{"пить чай; идти"}},
+		{"помощь заг", nil},
+		{"идти коридор; идти к", []string{"идти коридор; идти коридор"}}, // дополняем по текущему положению
+	}
+	for _, c := range cases {
+		if got := gameState.Complete(c.prefix); strings.Join(got, "|") != strings.Join(c.expected, "|") {
+			t.Errorf("Complete(%q) = %q, ожидалось %q", c.prefix, got, c.expected)
+		}
+	}
+
+	for _, command := range []string{"идти коридор", "идти комната", "надеть рюкзак", "взять ключи"} {
+		gameState.HandleCommand(command)
+	}
+	cases = []struct {
+		prefix   string
+		expected []string
+	}{
+		{"взять ", []string{"взять конспекты"}},
+		{"открыть ", []string{"открыть шкаф"}},
+		{"применить ", []string{"применить ключи"}}, // рюкзак надет, а не в руках
+		{"зажечь ", nil}, // ни фонарика, ни свечи
+		{"применить ключи ", []string{"применить ключи конспекты"}},
+		{"идти до ", []string{"идти до комната", "идти до коридор", "идти до кухня", "идти до улица"}},
+		{"взять конспекты ", nil},
+	}
+	for _, c := range cases {
+		if got := gameState.Complete(c.prefix); strings.Join(got, "|") != strings.Join(c.expected, "|") {
+			t.Errorf("Complete(%q) = %q, ожидалось %q", c.prefix, got, c.expected)
+		}
+	}
+
+	// В темноте выходов не видно
+	gameState.Player.CurrentRoom.SetTrait("dark", true)
+	for _, prefix := range []string{"идти ", "идти до "} {
+		if got := gameState.Complete(prefix); got != nil {
+			t.Errorf("Complete(%q) в темноте = %q, ожидалось ничего", prefix, got)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	initGame()
+	cases := []struct {
+		command string
+		status  game.Status
+		code    string
+		deltas  []game.Delta
+	}{
+		{"прыгнуть", game.StatusError, game.CodeUnknownCommand, nil},
+		{"взять", game.StatusError, game.CodeMissingArg, nil},
+		{"идти до", game.StatusError, game.CodeMissingArg, nil},
+		{"взять чай", game.StatusError, game.CodeNoBackpack, nil},
+		{"идти коридор", game.StatusOK, "", []game.Delta{{Kind: game.DeltaMoved, Subject: "игрок", Key: "кухня", Value: "коридор"}}},
+		{"идти улица", game.StatusBlocked, game.CodeRuleBlocked, nil},
+		{"идти комната; надеть рюкзак; взять ключи", game.StatusOK, "", []game.Delta{
+			{Kind: game.DeltaMoved, Subject: "игрок", Key: "коридор", Value: "комната"},
+			{Kind: game.DeltaItemRemoved, Subject: "рюкзак", Key: "стуле"},
+			{Kind: game.DeltaItemAdded, Subject: "рюкзак", Key: game.LocationWorn},
+			{Kind: game.DeltaItemRemoved, Subject: "ключи", Key: "столе"},
+			{Kind: game.DeltaItemAdded, Subject: "ключи", Key: game.LocationInventory},
+		}},
+		{"взять шапку; открыть шкаф", game.StatusError, game.CodeNotFound, []game.Delta{
+			{Kind: game.DeltaTraitChanged, Subject: "шкаф", Key: "opened", Value: true},
+		}},
+		{"открыть шкаф", game.StatusError, game.CodeAlreadyDone, nil},
+		{"положить ключи в шкаф", game.StatusOK, "", []game.Delta{
+			{Kind: game.DeltaItemRemoved, Subject: "ключи", Key: game.LocationInventory},
+			{Kind: game.DeltaItemAdded, Subject: "ключи", Key: "шкафу"},
+		}},
+		{"взять куртка; надеть куртка", game.StatusOK, "", []game.Delta{
+			{Kind: game.DeltaItemRemoved, Subject: "куртка", Key: "шкафу"},
+			{Kind: game.DeltaItemAdded, Subject: "куртка", Key: game.LocationInventory},
+			{Kind: game.DeltaItemRemoved, Subject: "куртка", Key: game.LocationInventory},
+			{Kind: game.DeltaItemAdded, Subject: "куртка", Key: game.LocationWorn},
+		}},
+		{"взять фонарик; включить фонарик", game.StatusOK, "", []game.Delta{
+			{Kind: game.DeltaItemRemoved, Subject: "фонарик", Key: "шкафу"},
+			{Kind: game.DeltaItemAdded, Subject: "фонарик", Key: game.LocationInventory},
+			{Kind: game.DeltaTraitChanged, Subject: "фонарик", Key: "lit", Value: true},
+		}},
+	}
+
+	for _, c := range cases {
+		result := gameState.Execute(c.command)
+		if result.Status != c.status || result.Code != c.code {
+			t.Errorf("[%s] статус %s/%q, ожидалось %s/%q: %s", c.command, result.Status, result.Code, c.status, c.code, result.Text)
+		}
+		if len(result.Deltas) != len(c.deltas) {
+			t.Errorf("[%s] изменения %v, ожидалось %v", c.command, result.Deltas, c.deltas)
+			continue
+		}
+		for i, delta := range result.Deltas {
+			if delta != c.deltas[i] {
+				t.Errorf("[%s] изменение %v, ожидалось %v", c.command, delta, c.deltas[i])
+			}
+		}
+	}
+
+	if answer := gameState.HandleCommand("осмотреться"); answer != gameState.Execute("осмотреться").Text {
+		t.Error("HandleCommand и Execute отвечают по-разному:", answer)
+	}
+
+	// Догоревший фонарик и выпитый чай тоже попадают в изменения
+	gameState.Player.GetItem("фонарик").LightSource().Fuel = 1
+	result := gameState.Execute("идти коридор; идти кухня; пить чай")
+	expected := []game.Delta{
+		{Kind: game.DeltaMoved, Subject: game.SubjectPlayer, Key: "комната", Value: "коридор"},
+		{Kind: game.DeltaTraitChanged, Subject: "фонарик", Key: "lit", Value: false},
+		{Kind: game.DeltaMoved, Subject: game.SubjectPlayer, Key: "коридор", Value: "кухня"},
+		{Kind: game.DeltaItemRemoved, Subject: "чай", Key: "столе"},
+		{Kind: game.DeltaTraitChanged, Subject: game.SubjectPlayer, Key: entity.AttrEnergy, Value: 65},
+		{Kind: game.DeltaTraitChanged, Subject: game.SubjectPlayer, Key: entity.AttrHunger, Value: 30},
+		{Kind: game.DeltaEffectAdded, Subject: game.SubjectPlayer, Key: game.EffectFed},
+	}
+	if !reflect.DeepEqual(result.Deltas, expected) {
+		t.Errorf("изменения %v, ожидалось %v", result.Deltas, expected)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBell      = "\a"
+	keyTab       = '\t'
+	keyBackspace = 127
+	keyCtrlH     = 8
+	keyEscape    = 27
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor читает строку из терминала в сыром режиме: сам печатает ввод и дополняет его по Tab.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	prompt   string
+	complete func(string) []string
+}
+
+func (e *lineEditor) ReadLine() (string, error) {
+	var line []rune
+	fmt.Fprint(e.out, e.prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return string(line), err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case keyBackspace, keyCtrlH:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Fprint(e.out, "\b \b")
+			}
+		case keyTab:
+			line = e.completeLine(line)
+		case keyEscape:
+			e.skipEscape()
+		default:
+			if r >= ' ' && r != utf8.RuneError {
+				line = append(line, r)
+				fmt.Fprint(e.out, string(r))
+			}
+		}
+	}
+}
+
+func (e *lineEditor) completeLine(line []rune) []rune {
+	candidates := e.complete(string(line))
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, keyBell)
+		return line
+	case 1:
+		return e.redraw(line, []rune(candidates[0]+" "))
+	}
+
+	if prefix := commonPrefix(candidates); len(prefix) > len(string(line)) {
+		return e.redraw(line, []rune(prefix))
+	}
+
+	words := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		words[i] = candidate[strings.LastIndex(candidate, " ")+1:]
+	}
+	fmt.Fprint(e.out, "\r\n"+strings.Join(words, "  ")+"\r\n"+e.prompt+string(line))
+	return line
+}
+
+func (e *lineEditor) redraw(old, line []rune) []rune {
+	fmt.Fprint(e.out, strings.Repeat("\b \b", len(old))+string(line))
+	return line
+}
+
+// skipEscape пропускает управляющие последовательности стрелок и прочих клавиш.
+func (e *lineEditor) skipEscape() {
+	if next, _, err := e.in.ReadRune(); err != nil || next != '[' {
+		return
+	}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil || (r >= '@' && r <= '~') {
+			return
+		}
+	}
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
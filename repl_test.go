@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor(t *testing.T) {
+	initGame()
+
+	var out bytes.Buffer
+	editor := &lineEditor{
+		in:       bufio.NewReader(strings.NewReader("вз\t\nосм\t\t\n\x1b[Aх\x7fвр\t\n\x04")),
+		out:      &out,
+		prompt:   "> ",
+		complete: gameState.Complete,
+	}
+
+	line, err := editor.ReadLine()
+	if err != nil || line != "взять " {
+		t.Errorf("первая строка: %q, %v", line, err)
+	}
+
+	line, err = editor.ReadLine()
+	if err != nil || line != "осмотреть" {
+		t.Errorf("вторая строка: %q, %v", line, err)
+	}
+	if !strings.Contains(out.String(), "осмотреть  осмотреться") {
+		t.Errorf("варианты не показаны: %q", out.String())
+	}
+
+	line, err = editor.ReadLine()
+	if err != nil || line != "время " {
+		t.Errorf("третья строка: %q, %v", line, err)
+	}
+
+	if _, err = editor.ReadLine(); err != io.EOF {
+		t.Errorf("ожидался конец ввода, получено %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// enableRawMode выключает построчный ввод и эхо терминала; для не-терминалов возвращает ошибку.
+func enableRawMode(file *os.File) (func(), error) {
+	var original syscall.Termios
+	if err := termios(file, syscall.TCGETS, &original); err != nil {
+		return nil, err
+	}
+
+	raw := original
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termios(file, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = termios(file, syscall.TCSETS, &original)
+	}, nil
+}
+
+func termios(file *os.File, request uintptr, state *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), request, uintptr(unsafe.Pointer(state)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func enableRawMode(file *os.File) (func(), error) {
+	return nil, errors.New("сырой режим терминала поддерживается только в linux")
+}
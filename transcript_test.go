@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Транскрипт - текстовый файл в testdata/transcripts:
+//
+//	# комментарий
+//	> команда
+//	ожидаемый ответ
+//	| следующая строка многострочного ответа
+//	? проверка состояния
+//
+// go test -run TestTranscripts -update перезаписывает ответы фактическими.
+var update = flag.Bool("update", false, "перезаписать ответы в транскриптах")
+
+// continuation начинает каждую следующую строку многострочного ответа.
+const continuation = "|"
+
+type transcriptStep struct {
+	comments []string
+	line     int
+	command  string
+	answer   string
+	checks   []string
+}
+
+type transcript struct {
+	steps    []*transcriptStep
+	trailing []string
+}
+
+var transcriptChecks = map[string]func(arg string) bool{
+	"инвентарь содержит":    func(arg string) bool { return gameState.Player.HasItem(arg) },
+	"инвентарь не содержит": func(arg string) bool { return !gameState.Player.HasItem(arg) },
+	"надето": func(arg string) bool {
+		for _, item := range gameState.Player.WornItems {
+			if item.Name == arg {
+				return true
+			}
+		}
+		return false
+	},
+	"игрок в":         func(arg string) bool { return gameState.Player.CurrentRoom.Name == arg },
+	"в комнате есть":  func(arg string) bool { return gameState.Player.CurrentRoom.HasItem(arg) },
+	"в комнате нет":   func(arg string) bool { return !gameState.Player.CurrentRoom.HasItem(arg) },
+	"игра окончена":   func(arg string) bool { return gameState.GameOver },
+	"игра продолжает": func(arg string) bool { return !gameState.GameOver },
+}
+
+func TestTranscripts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "transcripts", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no transcripts found")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			script, err := readTranscript(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			initGame()
+			for _, step := range script.steps {
+				answer := handleCommand(step.command)
+				if *update {
+					step.answer = answer
+				} else if answer != step.answer {
+					expected, result := highlightDiff(step.answer, answer)
+					t.Errorf("%s:%d\n\tcmd:      %s\n\tresult:   %s\n\texpected: %s",
+						path, step.line, step.command, result, expected)
+				}
+
+				for _, check := range step.checks {
+					if err := runTranscriptCheck(check); err != nil {
+						t.Errorf("%s:%d\n\tcmd:   %s\n\tcheck: %s: %v", path, step.line, step.command, check, err)
+					}
+				}
+			}
+
+			if *update {
+				if err := writeTranscript(path, script); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func runTranscriptCheck(check string) error {
+	for prefix, assert := range transcriptChecks {
+		if check != prefix && !strings.HasPrefix(check, prefix+" ") {
+			continue
+		}
+		if !assert(strings.TrimSpace(strings.TrimPrefix(check, prefix))) {
+			return fmt.Errorf("не выполнено")
+		}
+		return nil
+	}
+	return fmt.Errorf("неизвестная проверка")
+}
+
+func readTranscript(path string) (*transcript, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	script := &transcript{}
+	var comments []string
+	var current *transcriptStep
+	answered := false
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#"):
+			comments = append(comments, line)
+		case strings.HasPrefix(line, ">"):
+			current = &transcriptStep{
+				comments: comments,
+				line:     lineNum,
+				command:  strings.TrimSpace(strings.TrimPrefix(line, ">")),
+			}
+			script.steps = append(script.steps, current)
+			comments = nil
+			answered = false
+		case current == nil:
+			return nil, fmt.Errorf("%s:%d: ожидалась команда", path, lineNum)
+		case strings.HasPrefix(line, "?"):
+			current.checks = append(current.checks, strings.TrimSpace(strings.TrimPrefix(line, "?")))
+		case strings.HasPrefix(line, continuation):
+			if !answered {
+				return nil, fmt.Errorf("%s:%d: продолжение без ответа", path, lineNum)
+			}
+			current.answer += "\n" + strings.TrimPrefix(strings.TrimPrefix(line, continuation), " ")
+		case !answered:
+			current.answer = line
+			answered = true
+		default:
+			return nil, fmt.Errorf("%s:%d: у команды уже есть ответ", path, lineNum)
+		}
+	}
+	script.trailing = comments
+	return script, scanner.Err()
+}
+
+func writeTranscript(path string, script *transcript) error {
+	var out strings.Builder
+	for _, step := range script.steps {
+		for _, comment := range step.comments {
+			out.WriteString(comment + "\n")
+		}
+		out.WriteString("> " + step.command + "\n")
+		for i, line := range strings.Split(step.answer, "\n") {
+			switch {
+			case i == 0:
+				out.WriteString(line + "\n")
+			case line == "":
+				out.WriteString(continuation + "\n")
+			default:
+				out.WriteString(continuation + " " + line + "\n")
+			}
+		}
+		for _, check := range step.checks {
+			out.WriteString("? " + check + "\n")
+		}
+	}
+	for _, comment := range script.trailing {
+		out.WriteString(comment + "\n")
+	}
+	return os.WriteFile(path, []byte(out.String()), 0o644)
+}
+
+// highlightDiff выделяет отличающуюся часть строк посимвольно (по рунам, а не байтам),
+// чтобы разница в кириллице не превращалась в обрывки UTF-8.
+func highlightDiff(expected, actual string) (string, string) {
+	want, got := []rune(expected), []rune(actual)
+
+	prefix := 0
+	for prefix < len(want) && prefix < len(got) && want[prefix] == got[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(want)-prefix && suffix < len(got)-prefix &&
+		want[len(want)-1-suffix] == got[len(got)-1-suffix] {
+		suffix++
+	}
+
+	mark := func(text []rune, open, close string) string {
+		return string(text[:prefix]) + open + string(text[prefix:len(text)-suffix]) + close + string(text[len(text)-suffix:])
+	}
+	return mark(want, "[-", "-]"), mark(got, "{+", "+}")
+}